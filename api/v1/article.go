@@ -2,9 +2,12 @@ package v1
 
 import (
 	"ginblog/model"
+	"ginblog/utils"
 	"ginblog/utils/errmsg"
+	"ginblog/utils/markdown"
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"path"
 	"strconv"
 )
 
@@ -17,7 +20,15 @@ func AddArticle(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求数据"})
 		return
 	}
-	code := model.CreateArt(ctx, &data)
+	user, code := currentUser(c)
+	if code != errmsg.Success {
+		c.JSON(http.StatusOK, gin.H{
+			"status":  code,
+			"message": errmsg.GetErrMsg(code),
+		})
+		return
+	}
+	code = model.CreateArt(ctx, &data, user)
 
 	c.JSON(http.StatusOK, gin.H{
 		"status":  code,
@@ -44,7 +55,7 @@ func GetCateArt(c *gin.Context) {
 		pageNum = 1
 	}
 
-	data, code, total := model.GetCateArt(ctx, id, pageSize, pageNum)
+	data, code, total := model.GetCateArt(ctx, id, pageSize, pageNum, articleStatusFilter(c), c.Query("sort"), c.Query("order"))
 
 	c.JSON(http.StatusOK, gin.H{
 		"status":  code,
@@ -58,7 +69,36 @@ func GetCateArt(c *gin.Context) {
 func GetArtInfo(c *gin.Context) {
 	ctx := c.Request.Context()
 	id, _ := strconv.Atoi(c.Param("id"))
-	data, code := model.GetArtInfo(ctx, id)
+	data, code := model.GetArtInfo(ctx, id, articleStatusFilter(c))
+	if code == errmsg.Success {
+		c.Header("ETag", etag(data.ID, data.Version))
+		attachLock(c, &data)
+	}
+	selectContentFormat(c, &data)
+	c.JSON(http.StatusOK, gin.H{
+		"status":  code,
+		"data":    data,
+		"message": errmsg.GetErrMsg(code),
+	})
+}
+
+// GetArtBySlug 按别名查询单个文章信息，历史别名重定向到当前别名
+func GetArtBySlug(c *gin.Context) {
+	ctx := c.Request.Context()
+	data, redirect, code := model.GetArtBySlug(ctx, c.Param("slug"), articleStatusFilter(c))
+	if redirect != "" {
+		target := path.Join(path.Dir(c.Request.URL.Path), redirect)
+		if c.Request.URL.RawQuery != "" {
+			target += "?" + c.Request.URL.RawQuery
+		}
+		c.Redirect(http.StatusMovedPermanently, target)
+		return
+	}
+	if code == errmsg.Success {
+		c.Header("ETag", etag(data.ID, data.Version))
+		attachLock(c, &data)
+	}
+	selectContentFormat(c, &data)
 	c.JSON(http.StatusOK, gin.H{
 		"status":  code,
 		"data":    data,
@@ -66,6 +106,34 @@ func GetArtInfo(c *gin.Context) {
 	})
 }
 
+// articleStatusFilter 确定查询的文章状态
+// 前台接口只返回已发布的文章；后台接口（已登录）默认返回全部状态，可通过 status 参数筛选
+func articleStatusFilter(c *gin.Context) int {
+	if c.GetString("username") == "" {
+		return model.ArticlePublished
+	}
+	status, _ := strconv.Atoi(c.Query("status"))
+	return status
+}
+
+// attachLock 后台接口（已登录）返回文章当前的编辑锁
+func attachLock(c *gin.Context, data *model.Article) {
+	if c.GetString("username") != "" {
+		data.Lock = model.GetArticleLock(c.Request.Context(), int(data.ID))
+	}
+}
+
+// selectContentFormat 按 format 参数选择返回的正文格式
+// format=markdown 只返回源文本，format=html 只返回渲染结果，默认两者都返回
+func selectContentFormat(c *gin.Context, data *model.Article) {
+	switch c.Query("format") {
+	case "markdown":
+		data.ContentHtml = ""
+	case "html":
+		data.Content = ""
+	}
+}
+
 // GetArt 查询文章列表
 func GetArt(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -84,7 +152,7 @@ func GetArt(c *gin.Context) {
 		pageNum = 1
 	}
 	if len(title) == 0 {
-		data, code, total := model.GetArt(ctx, pageSize, pageNum)
+		data, code, total := model.GetArt(ctx, pageSize, pageNum, articleStatusFilter(c), c.Query("sort"), c.Query("order"))
 		c.JSON(http.StatusOK, gin.H{
 			"status":  code,
 			"data":    data,
@@ -94,7 +162,7 @@ func GetArt(c *gin.Context) {
 		return
 	}
 
-	data, code, total := model.SearchArticle(ctx, title, pageSize, pageNum)
+	data, code, total := model.SearchArticle(ctx, title, pageSize, pageNum, articleStatusFilter(c), c.Query("sort"), c.Query("order"))
 	c.JSON(http.StatusOK, gin.H{
 		"status":  code,
 		"data":    data,
@@ -103,14 +171,99 @@ func GetArt(c *gin.Context) {
 	})
 }
 
-// EditArt 编辑文章
+// EditArt 编辑文章，通过 If-Match 请求头或请求体中的 version 检测编辑冲突
 func EditArt(c *gin.Context) {
 	ctx := c.Request.Context()
 	var data model.Article
 	id, _ := strconv.Atoi(c.Param("id"))
 	_ = c.ShouldBindJSON(&data)
 
-	code := model.EditArt(ctx, id, &data)
+	user, code := currentUser(c)
+	if code != errmsg.Success {
+		c.JSON(http.StatusOK, gin.H{
+			"status":  code,
+			"message": errmsg.GetErrMsg(code),
+		})
+		return
+	}
+	data.Version = expectedVersion(c, id, data.Version)
+	code = model.EditArt(ctx, id, &data, user)
+	articleSaveResult(c, id, data.Version, code)
+}
+
+// articleSaveResult 返回保存文章内容的结果：成功时设置新版本的 ETag；
+// 版本冲突时返回服务端当前内容和 ETag，由客户端合并后重新提交
+func articleSaveResult(c *gin.Context, id int, version int, code int) {
+	if code == errmsg.ErrorArtVersionConflict {
+		current, _ := model.GetArtInfo(c.Request.Context(), id, 0)
+		c.Header("ETag", etag(current.ID, current.Version))
+		c.JSON(http.StatusOK, gin.H{
+			"status":  code,
+			"data":    current,
+			"message": errmsg.GetErrMsg(code),
+		})
+		return
+	}
+	if code == errmsg.Success {
+		c.Header("ETag", etag(uint(id), version))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  code,
+		"message": errmsg.GetErrMsg(code),
+	})
+}
+
+// PublishArt 发布文章
+func PublishArt(c *gin.Context) {
+	ctx := c.Request.Context()
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	code := model.PublishArt(ctx, id)
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  code,
+		"message": errmsg.GetErrMsg(code),
+	})
+}
+
+// UnpublishArt 撤回文章为草稿
+func UnpublishArt(c *gin.Context) {
+	ctx := c.Request.Context()
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	code := model.UnpublishArt(ctx, id)
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  code,
+		"message": errmsg.GetErrMsg(code),
+	})
+}
+
+// ArchiveArt 归档文章
+func ArchiveArt(c *gin.Context) {
+	ctx := c.Request.Context()
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	code := model.ArchiveArt(ctx, id)
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  code,
+		"message": errmsg.GetErrMsg(code),
+	})
+}
+
+// ScheduleArt 设置文章的定时发布时间和过期时间
+func ScheduleArt(c *gin.Context) {
+	ctx := c.Request.Context()
+	var data model.ScheduleForm
+	id, _ := strconv.Atoi(c.Param("id"))
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求数据"})
+		return
+	}
+
+	code := model.ScheduleArt(ctx, id, &data)
 
 	c.JSON(http.StatusOK, gin.H{
 		"status":  code,
@@ -130,3 +283,26 @@ func DeleteArt(c *gin.Context) {
 		"message": errmsg.GetErrMsg(code),
 	})
 }
+
+// GetHighlightCSS 获取代码高亮样式表，theme 参数指定配色主题
+func GetHighlightCSS(c *gin.Context) {
+	css, err := markdown.HighlightCSS(c.Query("theme"), utils.HighlightTheme)
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"status":  errmsg.Error,
+			"message": errmsg.GetErrMsg(errmsg.Error),
+		})
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.Data(http.StatusOK, "text/css; charset=utf-8", []byte(css))
+}
+
+// GetHighlightThemes 查询可选的代码高亮配色主题
+func GetHighlightThemes(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":  errmsg.Success,
+		"data":    markdown.HighlightThemes(),
+		"message": errmsg.GetErrMsg(errmsg.Success),
+	})
+}
@@ -0,0 +1,84 @@
+package v1
+
+import (
+	"ginblog/model"
+	"ginblog/utils/errmsg"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// SaveAutosave 自动保存文章的工作副本（不修改文章本身）
+func SaveAutosave(c *gin.Context) {
+	ctx := c.Request.Context()
+	var data model.ArticleAutosave
+	id, _ := strconv.Atoi(c.Param("id"))
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求数据"})
+		return
+	}
+
+	user, code := currentUser(c)
+	if code == errmsg.Success {
+		data.Version = expectedVersion(c, id, data.Version)
+		code = model.SaveAutosave(ctx, id, &data, user)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  code,
+		"data":    data,
+		"message": errmsg.GetErrMsg(code),
+	})
+}
+
+// GetAutosave 查询当前用户对文章的最新工作副本
+func GetAutosave(c *gin.Context) {
+	ctx := c.Request.Context()
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	var data model.ArticleAutosave
+	user, code := currentUser(c)
+	if code == errmsg.Success {
+		data, code = model.GetAutosave(ctx, id, user)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  code,
+		"data":    data,
+		"message": errmsg.GetErrMsg(code),
+	})
+}
+
+// DeleteAutosave 丢弃当前用户对文章的工作副本
+func DeleteAutosave(c *gin.Context) {
+	ctx := c.Request.Context()
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	user, code := currentUser(c)
+	if code == errmsg.Success {
+		code = model.DeleteAutosave(ctx, id, user)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  code,
+		"message": errmsg.GetErrMsg(code),
+	})
+}
+
+// PublishAutosave 将工作副本的内容保存到文章
+// If-Match 请求头指定期望的文章版本，未指定时使用副本开始编辑时的版本
+func PublishAutosave(c *gin.Context) {
+	ctx := c.Request.Context()
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	user, code := currentUser(c)
+	if code != errmsg.Success {
+		c.JSON(http.StatusOK, gin.H{
+			"status":  code,
+			"message": errmsg.GetErrMsg(code),
+		})
+		return
+	}
+	version, code := model.PublishAutosave(ctx, id, expectedVersion(c, id, 0), user)
+	articleSaveResult(c, id, version, code)
+}
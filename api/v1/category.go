@@ -33,6 +33,9 @@ func GetCateInfo(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
 
 	data, code := model.GetCateInfo(ctx, id)
+	if data.ID != 0 {
+		c.Header("ETag", etag(data.ID, data.Version))
+	}
 
 	c.JSON(
 		http.StatusOK, gin.H{
@@ -81,11 +84,17 @@ func EditCate(c *gin.Context) {
 	_ = c.ShouldBindJSON(&data)
 	code := model.CheckUpCategory(ctx, id, data.Name)
 	if code == errmsg.Success {
-		model.EditCate(ctx, id, &data)
+		data.Version = expectedVersion(c, id, data.Version)
+		code = model.EditCate(ctx, id, &data)
 	}
 	if code == errmsg.ErrorCatenameUsed {
 		c.Abort()
 	}
+	// 保存成功时返回新版本；版本冲突时返回服务端当前内容，由客户端合并后重新提交
+	if code == errmsg.Success || code == errmsg.ErrorCateVersionConflict {
+		data, _ = model.GetCateInfo(ctx, id)
+		c.Header("ETag", etag(data.ID, data.Version))
+	}
 
 	c.JSON(
 		http.StatusOK, gin.H{
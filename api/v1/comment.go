@@ -0,0 +1,192 @@
+package v1
+
+import (
+	"ginblog/model"
+	"ginblog/utils/errmsg"
+	"ginblog/utils/validator"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// AddComment 新增评论
+func AddComment(c *gin.Context) {
+	ctx := c.Request.Context()
+	var data model.Comment
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求数据"})
+		return
+	}
+
+	msg, validCode := validator.Validate(&data)
+	if validCode != errmsg.Success {
+		c.JSON(http.StatusOK, gin.H{
+			"status":  validCode,
+			"message": msg,
+		})
+		c.Abort()
+		return
+	}
+
+	// 评论者取自登录令牌，忽略请求中的 user_id
+	user, code := currentUser(c)
+	if code == errmsg.Success {
+		code = model.AddComment(ctx, &data, user)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  code,
+		"data":    data,
+		"message": errmsg.GetErrMsg(code),
+	})
+}
+
+// GetComment 查询单个评论信息，前台接口只返回已审核且文章在前台可见的评论
+func GetComment(c *gin.Context) {
+	ctx := c.Request.Context()
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	data, code := model.GetComment(ctx, id, c.GetString("username") == "")
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  code,
+		"data":    data,
+		"message": errmsg.GetErrMsg(code),
+	})
+}
+
+// GetCommentList 后台查询评论列表
+func GetCommentList(c *gin.Context) {
+	ctx := c.Request.Context()
+	pageSize, _ := strconv.Atoi(c.Query("pagesize"))
+	pageNum, _ := strconv.Atoi(c.Query("pagenum"))
+
+	switch {
+	case pageSize >= 100:
+		pageSize = 100
+	case pageSize <= 0:
+		pageSize = 10
+	}
+
+	if pageNum == 0 {
+		pageNum = 1
+	}
+
+	data, total, code := model.GetCommentList(ctx, pageSize, pageNum)
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  code,
+		"data":    data,
+		"total":   total,
+		"message": errmsg.GetErrMsg(code),
+	})
+}
+
+// GetCommentListFront 前台查询文章下已审核的评论
+func GetCommentListFront(c *gin.Context) {
+	ctx := c.Request.Context()
+	id, _ := strconv.Atoi(c.Param("id"))
+	pageSize, _ := strconv.Atoi(c.Query("pagesize"))
+	pageNum, _ := strconv.Atoi(c.Query("pagenum"))
+
+	switch {
+	case pageSize >= 100:
+		pageSize = 100
+	case pageSize <= 0:
+		pageSize = 10
+	}
+
+	if pageNum == 0 {
+		pageNum = 1
+	}
+
+	data, total, code := model.GetCommentListFront(ctx, id, pageSize, pageNum)
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  code,
+		"data":    data,
+		"total":   total,
+		"message": errmsg.GetErrMsg(code),
+	})
+}
+
+// GetCommentTree 查询文章的评论树（按顶层评论分页）
+func GetCommentTree(c *gin.Context) {
+	ctx := c.Request.Context()
+	id, _ := strconv.Atoi(c.Param("id"))
+	pageSize, _ := strconv.Atoi(c.Query("pagesize"))
+	pageNum, _ := strconv.Atoi(c.Query("pagenum"))
+
+	switch {
+	case pageSize >= 100:
+		pageSize = 100
+	case pageSize <= 0:
+		pageSize = 10
+	}
+
+	if pageNum == 0 {
+		pageNum = 1
+	}
+
+	data, total, code := model.GetCommentTree(ctx, id, pageSize, pageNum)
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  code,
+		"data":    data,
+		"total":   total,
+		"message": errmsg.GetErrMsg(code),
+	})
+}
+
+// GetCommentCount 查询文章下已审核的评论数
+func GetCommentCount(c *gin.Context) {
+	ctx := c.Request.Context()
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	total := model.GetCommentCount(ctx, id)
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  errmsg.Success,
+		"total":   total,
+		"message": errmsg.GetErrMsg(errmsg.Success),
+	})
+}
+
+// DeleteComment 删除评论
+func DeleteComment(c *gin.Context) {
+	ctx := c.Request.Context()
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	code := model.DeleteComment(ctx, id)
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  code,
+		"message": errmsg.GetErrMsg(code),
+	})
+}
+
+// CheckComment 审核通过评论
+func CheckComment(c *gin.Context) {
+	ctx := c.Request.Context()
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	code := model.CheckComment(ctx, id)
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  code,
+		"message": errmsg.GetErrMsg(code),
+	})
+}
+
+// UncheckComment 撤下评论
+func UncheckComment(c *gin.Context) {
+	ctx := c.Request.Context()
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	code := model.UncheckComment(ctx, id)
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  code,
+		"message": errmsg.GetErrMsg(code),
+	})
+}
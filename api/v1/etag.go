@@ -0,0 +1,33 @@
+package v1
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"strconv"
+	"strings"
+)
+
+// etag 根据记录 ID 和版本生成 ETag，格式为 "<id>-<version>"
+func etag(id uint, version int) string {
+	return fmt.Sprintf(`"%d-%d"`, id, version)
+}
+
+// expectedVersion 确定编辑请求期望的版本
+// 优先使用 If-Match 请求头，其次使用请求体中的 version；都没有时返回 0，表示不检查版本。
+// If-Match 与当前记录 ID 不符或格式错误时返回 -1，使版本检查必定失败
+func expectedVersion(c *gin.Context, id int, bodyVersion int) int {
+	ifMatch := strings.TrimSpace(c.GetHeader("If-Match"))
+	if ifMatch == "" || ifMatch == "*" {
+		return bodyVersion
+	}
+	tag := strings.Trim(strings.TrimPrefix(ifMatch, "W/"), `"`)
+	idPart, versionPart, found := strings.Cut(tag, "-")
+	if !found || idPart != strconv.Itoa(id) {
+		return -1
+	}
+	version, err := strconv.Atoi(versionPart)
+	if err != nil || version <= 0 {
+		return -1
+	}
+	return version
+}
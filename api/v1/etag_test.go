@@ -0,0 +1,48 @@
+package v1
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestEtag(t *testing.T) {
+	if got := etag(12, 3); got != `"12-3"` {
+		t.Errorf("etag(12, 3) = %s", got)
+	}
+}
+
+func TestExpectedVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name        string
+		ifMatch     string
+		bodyVersion int
+		want        int
+	}{
+		{"无请求头使用请求体", "", 4, 4},
+		{"无请求头无请求体", "", 0, 0},
+		{"通配符使用请求体", "*", 4, 4},
+		{"强 ETag", `"12-3"`, 4, 3},
+		{"弱 ETag", `W/"12-3"`, 0, 3},
+		{"首尾空白", ` "12-3" `, 0, 3},
+		{"ID 不符", `"13-3"`, 0, -1},
+		{"缺少版本", `"12"`, 0, -1},
+		{"版本非数字", `"12-x"`, 0, -1},
+		{"版本为 0", `"12-0"`, 0, -1},
+		{"版本为负", `"12--1"`, 0, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("PUT", "/api/v1/article/12", nil)
+			if tt.ifMatch != "" {
+				c.Request.Header.Set("If-Match", tt.ifMatch)
+			}
+			if got := expectedVersion(c, 12, tt.bodyVersion); got != tt.want {
+				t.Errorf("expectedVersion(%q, %d) = %d, want %d", tt.ifMatch, tt.bodyVersion, got, tt.want)
+			}
+		})
+	}
+}
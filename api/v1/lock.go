@@ -0,0 +1,82 @@
+package v1
+
+import (
+	"ginblog/model"
+	"ginblog/utils/errmsg"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// AcquireLock 获取文章编辑锁，被他人持有时返回当前锁信息
+func AcquireLock(c *gin.Context) {
+	ctx := c.Request.Context()
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	var data *model.ArticleLock
+	user, code := currentUser(c)
+	if code == errmsg.Success {
+		data, code = model.AcquireLock(ctx, id, user)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  code,
+		"data":    data,
+		"message": errmsg.GetErrMsg(code),
+	})
+}
+
+// HeartbeatLock 续期本人持有的文章编辑锁
+func HeartbeatLock(c *gin.Context) {
+	ctx := c.Request.Context()
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	var data *model.ArticleLock
+	user, code := currentUser(c)
+	if code == errmsg.Success {
+		data, code = model.HeartbeatLock(ctx, id, user)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  code,
+		"data":    data,
+		"message": errmsg.GetErrMsg(code),
+	})
+}
+
+// ReleaseLock 释放本人持有的文章编辑锁
+func ReleaseLock(c *gin.Context) {
+	ctx := c.Request.Context()
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	user, code := currentUser(c)
+	if code == errmsg.Success {
+		code = model.ReleaseLock(ctx, id, user)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  code,
+		"message": errmsg.GetErrMsg(code),
+	})
+}
+
+// ForceLock 强制获取文章编辑锁（管理员）
+func ForceLock(c *gin.Context) {
+	ctx := c.Request.Context()
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	var data *model.ArticleLock
+	user, code := currentUser(c)
+	if code == errmsg.Success && !user.IsAdmin() {
+		code = errmsg.ErrorUserNoRight
+	}
+	if code == errmsg.Success {
+		data, code = model.ForceLock(ctx, id, user)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  code,
+		"data":    data,
+		"message": errmsg.GetErrMsg(code),
+	})
+}
@@ -35,7 +35,7 @@ func Login(c *gin.Context) {
 
 }
 
-// LoginFront 前台登录
+// LoginFront 前台登录，签发的令牌可用于提交评论等前台接口，后台接口需要管理员身份
 func LoginFront(c *gin.Context) {
 	// 获取请求上下文
 	ctx := c.Request.Context()
@@ -44,6 +44,10 @@ func LoginFront(c *gin.Context) {
 	var code int
 
 	formData, code = model.CheckLoginFront(ctx, formData.Username, formData.Password)
+	if code == errmsg.Success {
+		setToken(c, formData)
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"status":  code,
@@ -58,6 +62,7 @@ func setToken(c *gin.Context, user model.User) {
 	j := middleware.NewJWT()
 	claims := middleware.MyClaims{
 		Username: user.Username,
+		TokenVer: user.TokenVer,
 		RegisteredClaims: jwt.RegisteredClaims{
 			NotBefore: jwt.NewNumericDate(time.Now()),
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(7 * 24 * time.Hour)),
@@ -0,0 +1,67 @@
+package v1
+
+import (
+	"ginblog/model"
+	"ginblog/utils/errmsg"
+	"ginblog/utils/validator"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// GetProfile 查询用户个人资料
+func GetProfile(c *gin.Context) {
+	ctx := c.Request.Context()
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	data, code := model.GetProfile(ctx, id)
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  code,
+		"data":    data,
+		"message": errmsg.GetErrMsg(code),
+	})
+}
+
+// UpdateProfile 更新个人资料
+// 普通用户只能修改自己的资料，管理员可以修改任意用户
+func UpdateProfile(c *gin.Context) {
+	ctx := c.Request.Context()
+	var data model.Profile
+	id, _ := strconv.Atoi(c.Param("id"))
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求数据"})
+		return
+	}
+
+	user, code := currentUser(c)
+	if code == errmsg.Success && user.ID != uint(id) && !user.IsAdmin() {
+		code = errmsg.ErrorUserNoRight
+	}
+	if code != errmsg.Success {
+		c.JSON(http.StatusOK, gin.H{
+			"status":  code,
+			"message": errmsg.GetErrMsg(code),
+		})
+		c.Abort()
+		return
+	}
+
+	msg, validCode := validator.Validate(&data)
+	if validCode != errmsg.Success {
+		c.JSON(http.StatusOK, gin.H{
+			"status":  validCode,
+			"message": msg,
+		})
+		c.Abort()
+		return
+	}
+
+	code = model.UpdateProfile(ctx, id, &data)
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  code,
+		"data":    data,
+		"message": errmsg.GetErrMsg(code),
+	})
+}
@@ -0,0 +1,86 @@
+package v1
+
+import (
+	"ginblog/model"
+	"ginblog/utils/errmsg"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// GetRevisions 查询文章的版本列表
+func GetRevisions(c *gin.Context) {
+	ctx := c.Request.Context()
+	pageSize, _ := strconv.Atoi(c.Query("pagesize"))
+	pageNum, _ := strconv.Atoi(c.Query("pagenum"))
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	switch {
+	case pageSize >= 100:
+		pageSize = 100
+	case pageSize <= 0:
+		pageSize = 10
+	}
+
+	if pageNum == 0 {
+		pageNum = 1
+	}
+
+	data, total, code := model.GetRevisions(ctx, id, pageSize, pageNum)
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  code,
+		"data":    data,
+		"total":   total,
+		"message": errmsg.GetErrMsg(code),
+	})
+}
+
+// GetRevision 查询文章的指定版本
+func GetRevision(c *gin.Context) {
+	ctx := c.Request.Context()
+	id, _ := strconv.Atoi(c.Param("id"))
+	number, _ := strconv.Atoi(c.Param("number"))
+
+	data, code := model.GetRevision(ctx, id, number)
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  code,
+		"data":    data,
+		"message": errmsg.GetErrMsg(code),
+	})
+}
+
+// DiffRevisions 比较文章的两个版本，from 和 to 为版本号
+func DiffRevisions(c *gin.Context) {
+	ctx := c.Request.Context()
+	id, _ := strconv.Atoi(c.Param("id"))
+	from, _ := strconv.Atoi(c.Query("from"))
+	to, _ := strconv.Atoi(c.Query("to"))
+
+	data, code := model.DiffRevisions(ctx, id, from, to)
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  code,
+		"data":    data,
+		"message": errmsg.GetErrMsg(code),
+	})
+}
+
+// RestoreRevision 将文章恢复为指定版本，If-Match 请求头指定期望的文章版本
+func RestoreRevision(c *gin.Context) {
+	ctx := c.Request.Context()
+	id, _ := strconv.Atoi(c.Param("id"))
+	number, _ := strconv.Atoi(c.Param("number"))
+
+	user, code := currentUser(c)
+	if code != errmsg.Success {
+		c.JSON(http.StatusOK, gin.H{
+			"status":  code,
+			"message": errmsg.GetErrMsg(code),
+		})
+		return
+	}
+	version, code := model.RestoreRevision(ctx, id, number, expectedVersion(c, id, 0), user)
+	articleSaveResult(c, id, version, code)
+}
@@ -2,28 +2,254 @@
 package v1
 
 import (
+	"errors"
 	"ginblog/model"
+	"ginblog/utils"
 	"ginblog/utils/errmsg"
+	"ginblog/utils/validator"
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"strconv"
 )
 
 // UpLoad 图片上传API接口
 // 路由处理函数，接收客户端上传的文件并返回存储结果
 // c - Gin上下文对象，包含请求和响应信息
 func UpLoad(c *gin.Context) {
+	ctx := c.Request.Context()
+	user, code := currentUser(c)
+	if code != errmsg.Success {
+		c.JSON(http.StatusOK, gin.H{
+			"status":  code,
+			"message": errmsg.GetErrMsg(code),
+		})
+		return
+	}
+
+	// 限制请求体大小（预留 1MB 给表单其他字段）
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, utils.UploadMaxSize+1<<20)
+
 	// 从表单中获取文件对象
-	file, fileHeader, _ := c.Request.FormFile("file")
-	// 获取文件大小
-	fileSize := fileHeader.Size
+	file, fileHeader, err := c.Request.FormFile("file")
+	if err != nil {
+		code = errmsg.ErrorUploadNoFile
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			code = errmsg.ErrorUploadTooLarge
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"status":  code,
+			"message": errmsg.GetErrMsg(code),
+		})
+		return
+	}
+	defer file.Close()
+
+	// 校验文件大小、类型和扩展名
+	var contentType string
+	contentType, code = model.CheckUploadFile(file, fileHeader.Filename, fileHeader.Size, utils.UploadMaxSize)
+	if code != errmsg.Success {
+		c.JSON(http.StatusOK, gin.H{
+			"status":  code,
+			"message": errmsg.GetErrMsg(code),
+		})
+		return
+	}
 
 	// 调用模型层上传文件
-	url, code := model.UpLoadFile(file, fileSize)
+	media, code := model.UpLoadFile(ctx, file, fileHeader.Size, contentType, user)
 
 	// 返回JSON响应
 	c.JSON(http.StatusOK, gin.H{
-		"status":  code,                   // 状态码
-		"message": errmsg.GetErrMsg(code), // 状态消息
-		"url":     url,                    // 文件访问URL
+		"status":   code,                   // 状态码
+		"message":  errmsg.GetErrMsg(code), // 状态消息
+		"url":      media.Url,              // 文件访问URL
+		"variants": media.Variants,         // 图片缩略图URL（按宽度升序）
+		"data":     media,                  // 媒体库记录
+	})
+}
+
+// InitChunkUpload 创建分片上传会话
+// 请求体：{"file_name": "文件名", "size": 文件大小, "chunk_size": 期望的分片大小}
+// 返回上传ID、实际分片大小和分片数量
+func InitChunkUpload(c *gin.Context) {
+	ctx := c.Request.Context()
+	var data model.UploadSession
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求数据"})
+		return
+	}
+
+	user, code := currentUser(c)
+	if code != errmsg.Success {
+		c.JSON(http.StatusOK, gin.H{
+			"status":  code,
+			"message": errmsg.GetErrMsg(code),
+		})
+		return
+	}
+
+	msg, validCode := validator.Validate(&data)
+	if validCode != errmsg.Success {
+		c.JSON(http.StatusOK, gin.H{
+			"status":  validCode,
+			"message": msg,
+		})
+		c.Abort()
+		return
+	}
+
+	data.UserId = user.ID
+	code = model.CreateUploadSession(ctx, &data)
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  code,
+		"data":    data,
+		"message": errmsg.GetErrMsg(code),
+	})
+}
+
+// PutChunk 上传一个分片，请求体为分片的原始字节
+func PutChunk(c *gin.Context) {
+	ctx := c.Request.Context()
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"status":  errmsg.ErrorUploadChunkInvalid,
+			"message": errmsg.GetErrMsg(errmsg.ErrorUploadChunkInvalid),
+		})
+		return
+	}
+
+	session, code := chunkSession(c)
+	if code == errmsg.Success {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, session.ChunkSize)
+		code = model.PutChunk(ctx, &session, index, c.Request.Body)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  code,
+		"message": errmsg.GetErrMsg(code),
+	})
+}
+
+// GetChunkUpload 查询上传会话及已接收的分片序号
+func GetChunkUpload(c *gin.Context) {
+	session, code := chunkSession(c)
+	var chunks []int
+	if code == errmsg.Success {
+		chunks, code = model.ReceivedChunks(&session)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  code,
+		"data":    session,
+		"chunks":  chunks,
+		"message": errmsg.GetErrMsg(code),
+	})
+}
+
+// CompleteChunkUpload 合并分片并保存文件，返回结果与 UpLoad 一致
+func CompleteChunkUpload(c *gin.Context) {
+	ctx := c.Request.Context()
+	user, code := currentUser(c)
+	var session model.UploadSession
+	if code == errmsg.Success {
+		session, code = model.GetUploadSession(ctx, c.Param("id"), user.ID)
+	}
+	if code != errmsg.Success {
+		c.JSON(http.StatusOK, gin.H{
+			"status":  code,
+			"message": errmsg.GetErrMsg(code),
+		})
+		return
+	}
+
+	media, code := model.CompleteUploadSession(ctx, &session, user)
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":   code,
+		"message":  errmsg.GetErrMsg(code),
+		"url":      media.Url,
+		"variants": media.Variants,
+		"data":     media,
+	})
+}
+
+// chunkSession 查询当前用户路由参数中指定的上传会话
+func chunkSession(c *gin.Context) (model.UploadSession, int) {
+	user, code := currentUser(c)
+	if code != errmsg.Success {
+		return model.UploadSession{}, code
+	}
+	return model.GetUploadSession(c.Request.Context(), c.Param("id"), user.ID)
+}
+
+// PresignUpload 申请浏览器直传凭证
+// 返回直传凭证（data.upload）和回调凭证（data.token），相同内容在回调时由服务端去重
+func PresignUpload(c *gin.Context) {
+	ctx := c.Request.Context()
+	var data model.PresignRequest
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求数据"})
+		return
+	}
+
+	user, code := currentUser(c)
+	if code != errmsg.Success {
+		c.JSON(http.StatusOK, gin.H{
+			"status":  code,
+			"message": errmsg.GetErrMsg(code),
+		})
+		return
+	}
+
+	msg, validCode := validator.Validate(&data)
+	if validCode != errmsg.Success {
+		c.JSON(http.StatusOK, gin.H{
+			"status":  validCode,
+			"message": msg,
+		})
+		c.Abort()
+		return
+	}
+
+	result, code := model.PresignUpload(ctx, &data, user)
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  code,
+		"data":    result,
+		"message": errmsg.GetErrMsg(code),
+	})
+}
+
+// PresignCallback 直传完成回调，记录已上传的文件
+// 请求体：{"token": "申请直传凭证时返回的回调凭证"}
+func PresignCallback(c *gin.Context) {
+	ctx := c.Request.Context()
+	var data struct {
+		Token string `json:"token"`
+	}
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求数据"})
+		return
+	}
+
+	user, code := currentUser(c)
+	if code != errmsg.Success {
+		c.JSON(http.StatusOK, gin.H{
+			"status":  code,
+			"message": errmsg.GetErrMsg(code),
+		})
+		return
+	}
+
+	media, code := model.CompletePresignUpload(ctx, data.Token, user)
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  code,
+		"message": errmsg.GetErrMsg(code),
+		"url":     media.Url,
+		"data":    media,
 	})
 }
@@ -157,3 +157,124 @@ func DeleteUser(c *gin.Context) {
 		},
 	)
 }
+
+// PasswordForm 修改密码请求参数
+type PasswordForm struct {
+	OldPassword string `json:"old_password" validate:"required,max=120" label:"旧密码"`
+	NewPassword string `json:"new_password" validate:"required,min=6,max=120" label:"新密码"`
+}
+
+// ResetPasswordForm 管理员重置密码请求参数
+type ResetPasswordForm struct {
+	NewPassword string `json:"new_password" validate:"required,min=6,max=120" label:"新密码"`
+}
+
+// ChangeUserPassword 修改密码
+// @Summary 修改密码
+// @Description 用户验证旧密码后修改自己的密码，修改后已签发的令牌全部失效
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Param form body PasswordForm true "旧密码与新密码"
+// @Success 200 {object} gin.H "{"status": 200, "message": "OK"}"
+// @Router /api/v1/changepw/{id} [put]
+func ChangeUserPassword(c *gin.Context) {
+	ctx := c.Request.Context()
+	var data PasswordForm
+	id, _ := strconv.Atoi(c.Param("id"))
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求数据"})
+		return
+	}
+
+	// 只能修改自己的密码，管理员重置他人密码请使用重置接口
+	user, code := currentUser(c)
+	if code == errmsg.Success && user.ID != uint(id) {
+		code = errmsg.ErrorUserNoRight
+	}
+	if code != errmsg.Success {
+		c.JSON(http.StatusOK, gin.H{
+			"status":  code,
+			"message": errmsg.GetErrMsg(code),
+		})
+		c.Abort()
+		return
+	}
+
+	msg, validCode := validator.Validate(&data)
+	if validCode != errmsg.Success {
+		c.JSON(http.StatusOK, gin.H{
+			"status":  validCode,
+			"message": msg,
+		})
+		c.Abort()
+		return
+	}
+
+	code = model.ChangePassword(ctx, id, data.OldPassword, data.NewPassword)
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  code,
+		"message": errmsg.GetErrMsg(code),
+	})
+}
+
+// ResetUserPassword 管理员重置密码
+// @Summary 重置密码
+// @Description 管理员直接为指定用户设置新密码，修改后该用户已签发的令牌全部失效
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Param form body ResetPasswordForm true "新密码"
+// @Success 200 {object} gin.H "{"status": 200, "message": "OK"}"
+// @Router /api/v1/admin/changepw/{id} [put]
+func ResetUserPassword(c *gin.Context) {
+	ctx := c.Request.Context()
+	var data ResetPasswordForm
+	id, _ := strconv.Atoi(c.Param("id"))
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求数据"})
+		return
+	}
+
+	user, code := currentUser(c)
+	if code == errmsg.Success && !user.IsAdmin() {
+		code = errmsg.ErrorUserNoRight
+	}
+	if code != errmsg.Success {
+		c.JSON(http.StatusOK, gin.H{
+			"status":  code,
+			"message": errmsg.GetErrMsg(code),
+		})
+		c.Abort()
+		return
+	}
+
+	msg, validCode := validator.Validate(&data)
+	if validCode != errmsg.Success {
+		c.JSON(http.StatusOK, gin.H{
+			"status":  validCode,
+			"message": msg,
+		})
+		c.Abort()
+		return
+	}
+
+	code = model.ResetPassword(ctx, id, data.NewPassword)
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  code,
+		"message": errmsg.GetErrMsg(code),
+	})
+}
+
+// currentUser 获取JWT中间件写入上下文的当前登录用户
+func currentUser(c *gin.Context) (model.User, int) {
+	username := c.GetString("username")
+	if username == "" {
+		return model.User{}, errmsg.ErrorTokenExist
+	}
+	return model.GetUserByName(c.Request.Context(), username)
+}
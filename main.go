@@ -8,6 +8,14 @@ import (
 func main() {
 	// 引用数据库
 	model.InitDb()
+	// 初始化文件存储
+	model.InitStorage()
+	// 启动后台任务
+	model.StartMediaGC()
+	model.StartUploadSessionGC()
+	model.StartArticleBackfill()
+	model.StartArticleScheduler()
+	model.StartAutosaveGC()
 	// 引入路由组件
 	routers.InitRouter()
 }
@@ -13,8 +13,8 @@ func Cors() gin.HandlerFunc {
 			//AllowAllOrigins:  true,
 			AllowOrigins:     []string{"*"}, // 等同于允许所有域名 #AllowAllOrigins:  true
 			AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-			AllowHeaders:     []string{"*", "Authorization"},
-			ExposeHeaders:    []string{"Content-Length", "text/plain", "Authorization", "Content-Type"},
+			AllowHeaders:     []string{"*", "Authorization", "If-Match"},
+			ExposeHeaders:    []string{"Content-Length", "text/plain", "Authorization", "Content-Type", "ETag"},
 			AllowCredentials: true,
 			MaxAge:           12 * time.Hour,
 		},
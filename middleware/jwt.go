@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"errors"
+	"ginblog/model"
 	"ginblog/utils"
 	"ginblog/utils/errmsg"
 	"github.com/gin-gonic/gin"
@@ -22,9 +23,10 @@ func NewJWT() *JWT {
 	}
 }
 
-// MyClaims 自定义 Claims 结构体，包含用户名和标准 Claims
+// MyClaims 自定义 Claims 结构体，包含用户名、令牌版本和标准 Claims
 type MyClaims struct {
 	Username string `json:"username"`
+	TokenVer int    `json:"token_ver"` // 令牌版本，与用户当前版本不一致时令牌失效
 	jwt.RegisteredClaims
 }
 
@@ -127,6 +129,17 @@ func JwtToken() gin.HandlerFunc {
 			return
 		}
 
+		// 校验令牌版本（修改密码后旧令牌失效）
+		if code = model.CheckTokenVer(c.Request.Context(), claims.Username, claims.TokenVer); code != errmsg.Success {
+			c.JSON(http.StatusOK, gin.H{
+				"status":  code,
+				"message": errmsg.GetErrMsg(code),
+				"data":    nil,
+			})
+			c.Abort()
+			return
+		}
+
 		// 将用户名存入 Gin 上下文，供后续处理使用
 		c.Set("username", claims.Username)
 		c.Next()
@@ -107,9 +107,9 @@ func Logger() gin.HandlerFunc {
 		//	requestBody = string(bodyBytes)
 		//	c.Request.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
 		//}
-		// 捕获请求体（支持重复读取）
+		// 捕获 JSON 请求体（支持重复读取），文件上传等二进制请求体不记录
 		var bodyBytes []byte
-		if c.Request.Body != nil {
+		if c.Request.Body != nil && c.ContentType() == gin.MIMEJSON {
 			bodyBytes, _ = io.ReadAll(c.Request.Body)
 			c.Request.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
 		}
@@ -177,8 +177,10 @@ func generateRequestID() string {
 func sanitizeBody(body string) string {
 	var data map[string]interface{}
 	if err := json.Unmarshal([]byte(body), &data); err == nil {
-		if _, ok := data["password"]; ok {
-			data["password"] = "******"
+		for _, key := range []string{"password", "old_password", "new_password"} {
+			if _, ok := data[key]; ok {
+				data[key] = "******"
+			}
 		}
 		sanitized, _ := json.Marshal(data)
 		return string(sanitized)
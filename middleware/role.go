@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"ginblog/model"
+	"ginblog/utils/errmsg"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// AdminOnly 管理员权限中间件，需在 JwtToken 之后使用
+// 前台登录签发的普通用户令牌只能访问评论等前台接口，不能访问后台管理接口
+func AdminOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, code := model.GetUserByName(c.Request.Context(), c.GetString("username"))
+		if code == errmsg.Success && !user.IsAdmin() {
+			code = errmsg.ErrorUserNoRight
+		}
+		if code != errmsg.Success {
+			c.JSON(http.StatusOK, gin.H{
+				"status":  code,
+				"message": errmsg.GetErrMsg(code),
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
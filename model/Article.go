@@ -2,58 +2,300 @@ package model
 
 import (
 	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"ginblog/utils"
 	"ginblog/utils/errmsg"
+	"ginblog/utils/markdown"
+	"ginblog/utils/sanitize"
+	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"strings"
+	"time"
+)
+
+// legacyAuthorRole 增加渲染功能前创建的文章的作者角色（当时只有管理员能登录后台发布文章）
+const legacyAuthorRole = 1
+
+// errVersionConflict 编辑时版本不一致（用于中止事务）
+var errVersionConflict = errors.New("version conflict")
+
+// 文章状态
+const (
+	ArticleDraft     = 1 // 草稿
+	ArticlePublished = 2 // 已发布
+	ArticleArchived  = 3 // 已归档
+	ArticleScheduled = 4 // 定时发布（到达 PublishAt 后发布）
 )
 
 type Article struct {
 	Category Category `gorm:"foreignkey:Cid;references:ID"`
 	gorm.Model
-	Title        string `gorm:"type:varchar(100);not null" json:"title"`
-	Cid          int    `gorm:"type:int;not null" json:"cid"`
-	Desc         string `gorm:"type:varchar(200)" json:"desc"`
-	Content      string `gorm:"type:longtext" json:"content"`
-	Img          string `gorm:"type:varchar(100)" json:"img"`
-	CommentCount int    `gorm:"type:int;not null;default:0" json:"comment_count"`
-	ReadCount    int    `gorm:"type:int;not null;default:0" json:"read_count"`
-}
-
-// CreateArt 新增文章
-func CreateArt(ctx context.Context, data *Article) int {
-	err := db.WithContext(ctx).Create(&data).Error
+	Title        string       `gorm:"type:varchar(100);not null" json:"title"`
+	Cid          int          `gorm:"type:int;not null" json:"cid"`
+	Desc         string       `gorm:"type:varchar(200)" json:"desc"`                          // 摘要（不含标签的纯文本，特殊字符已转义为实体）
+	Content      string       `gorm:"type:longtext" json:"content"`                           // Markdown 源文本
+	ContentHtml  string       `gorm:"type:longtext" json:"content_html"`                      // 保存时渲染的 HTML
+	Slug         string       `gorm:"type:varchar(100);default:null;uniqueIndex" json:"slug"` // 固定链接别名，未设置时根据标题生成
+	Toc          Toc          `gorm:"type:text" json:"toc"`                                   // 由标题生成的目录
+	WordCount    int          `gorm:"type:int;not null;default:0;index" json:"word_count"`    // 字数
+	ReadingTime  int          `gorm:"type:int;not null;default:0;index" json:"reading_time"`  // 预计阅读时间（分钟）
+	Img          string       `gorm:"type:varchar(300)" json:"img"`
+	Thumb        string       `gorm:"type:varchar(300)" json:"thumb"` // 封面缩略图URL（上传图片时生成）
+	CommentCount int          `gorm:"type:int;not null;default:0" json:"comment_count"`
+	ReadCount    int          `gorm:"type:int;not null;default:0" json:"read_count"`
+	Version      int          `gorm:"type:int;not null;default:1" json:"version"`          // 内容版本，每次编辑加 1，用于检测编辑冲突
+	Status       int8         `gorm:"type:tinyint;not null;default:2;index" json:"status"` // 1-草稿，2-已发布，3-已归档，4-定时发布
+	PublishedAt  *time.Time   `gorm:"index" json:"published_at"`                           // 首次发布时间
+	PublishAt    *time.Time   `gorm:"index" json:"publish_at"`                             // 定时发布时间
+	ExpireAt     *time.Time   `gorm:"index" json:"expire_at"`                              // 过期时间，到期后自动归档
+	Tags         []Tag        `gorm:"many2many:article_tag;" json:"tags"`
+	TagNames     []string     `gorm:"-" json:"tag_names,omitempty"` // 提交的标签名列表，首次使用时自动创建
+	Lock         *ArticleLock `gorm:"-" json:"lock,omitempty"`      // 当前编辑锁（仅后台查询时返回）
+}
+
+// AfterFind 没有缩略图的文章（如历史数据）使用封面原图；
+// 已到发布时间但定时任务尚未处理的文章按已发布返回
+func (a *Article) AfterFind(_ *gorm.DB) error {
+	if a.Thumb == "" {
+		a.Thumb = a.Img
+	}
+	if a.Status == ArticleScheduled && a.PublishAt != nil && !a.PublishAt.After(time.Now()) {
+		a.Status = ArticlePublished
+		if a.PublishedAt == nil {
+			a.PublishedAt = a.PublishAt
+		}
+	}
+	return nil
+}
+
+// Toc 文章目录，以 JSON 格式保存
+type Toc []markdown.TocItem
+
+// Value 实现 driver.Valuer 接口
+func (t Toc) Value() (driver.Value, error) {
+	if t == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(t)
+	return string(data), err
+}
+
+// Scan 实现 sql.Scanner 接口
+func (t *Toc) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("不支持的目录数据类型: %T", value)
+	}
+	if len(data) == 0 {
+		*t = nil
+		return nil
+	}
+	return json.Unmarshal(data, t)
+}
+
+// renderContent 渲染文章 Markdown 并按作者角色清理 HTML，同时更新目录和字数统计
+func (a *Article) renderContent(role int) error {
+	result, err := markdown.Render(a.Content, markdown.Options{
+		CjkPerMinute:   utils.CjkPerMinute,
+		WordsPerMinute: utils.WordsPerMinute,
+	})
+	if err != nil {
+		return err
+	}
+	a.ContentHtml = sanitize.HTML(result.HTML, sanitizePolicy(role))
+	a.Toc = result.Toc
+	a.WordCount = result.WordCount
+	a.ReadingTime = result.ReadingTime
+	return nil
+}
+
+// plainDesc 将摘要转换为转义后的纯文本并截断到字段长度（200 字符）
+func plainDesc(desc string) string {
+	return sanitize.Truncate(strings.TrimSpace(sanitize.Text(desc)), 200)
+}
+
+// BackfillArticles 为没有保存渲染结果或字数统计的历史文章补充渲染结果、目录和字数统计，返回处理数量
+// 增加渲染功能前只有管理员能登录后台发布文章，按管理员策略清理；已保存的渲染结果保持不变，只补充目录和字数统计；
+// 不修改文章的更新时间
+func BackfillArticles(ctx context.Context) (int, error) {
+	var batch []Article
+	count := 0
+	err := db.WithContext(ctx).Select("id", "content", "content_html").
+		Where("(content_html IS NULL OR content_html = '' OR word_count = 0) AND content <> ''").
+		FindInBatches(&batch, 100, func(tx *gorm.DB, _ int) error {
+			for i := range batch {
+				art := &batch[i]
+				rendered := art.ContentHtml
+				if err := art.renderContent(legacyAuthorRole); err != nil {
+					return err
+				}
+				if rendered != "" {
+					art.ContentHtml = rendered
+				}
+				err := db.WithContext(ctx).Model(art).UpdateColumns(map[string]interface{}{
+					"content_html": art.ContentHtml,
+					"toc":          art.Toc,
+					"word_count":   art.WordCount,
+					"reading_time": art.ReadingTime,
+				}).Error
+				if err != nil {
+					return err
+				}
+				count++
+			}
+			return nil
+		}).Error
+	return count, err
+}
+
+// StartArticleBackfill 启动历史文章渲染结果、别名和发布时间的回填任务
+func StartArticleBackfill() {
+	runOnce("article-backfill", func(ctx context.Context) error {
+		count, err := BackfillArticles(ctx)
+		if count > 0 {
+			logrus.WithField("Task", "article-backfill").Infof("已回填历史文章 %d 篇", count)
+		}
+		if err != nil {
+			return err
+		}
+		count, err = BackfillArticleSlugs(ctx)
+		if count > 0 {
+			logrus.WithField("Task", "article-backfill").Infof("已为历史文章生成别名 %d 个", count)
+		}
+		if err != nil {
+			return err
+		}
+		// 增加状态字段前的文章均为已发布，以创建时间作为发布时间
+		return db.WithContext(ctx).Model(&Article{}).Unscoped().
+			Where("status = ? AND published_at IS NULL", ArticlePublished).
+			UpdateColumn("published_at", gorm.Expr("created_at")).Error
+	})
+}
+
+// CreateArt 新增文章，新文章为草稿，需要发布后才在前台展示；渲染结果按作者角色清理，摘要转换为纯文本
+// 提交了发布时间时为定时发布，到时间后自动发布
+func CreateArt(ctx context.Context, data *Article, user User) int {
+	if code := validateSchedule(data.PublishAt, data.ExpireAt); code != errmsg.Success {
+		return code
+	}
+	if code := checkTagNames(data.TagNames); code != errmsg.Success {
+		return code
+	}
+	data.Status = ArticleDraft
+	if data.PublishAt != nil {
+		data.Status = ArticleScheduled
+	}
+	data.PublishedAt = nil
+	data.Version = 1
+	if err := data.renderContent(user.Role); err != nil {
+		return errmsg.Error
+	}
+	data.Desc = plainDesc(data.Desc)
+
+	var code int
+	data.Slug, code = resolveSlug(db.WithContext(ctx), 0, data.Slug, data.Title)
+	if code != errmsg.Success {
+		return code
+	}
+
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		tags, err := resolveTags(tx, data.TagNames)
+		if err != nil {
+			return err
+		}
+		data.Tags = tags
+		data.Thumb = ThumbURL(ctx, data.Img)
+		if err = tx.Create(&data).Error; err != nil {
+			return err
+		}
+		return createRevision(tx, data.ID, user.ID)
+	})
 	if err != nil {
 		return errmsg.Error
 	}
 	return errmsg.Success
 }
 
-// GetCateArt 查询分类下的所有文章
-func GetCateArt(ctx context.Context, id int, pageSize int, pageNum int) ([]Article, int, int64) {
+// articleSorts 文章列表允许的排序字段
+var articleSorts = map[string]string{
+	"created_at":    "article.created_at",
+	"read_count":    "article.read_count",
+	"comment_count": "article.comment_count",
+	"word_count":    "article.word_count",
+	"reading_time":  "article.reading_time",
+}
+
+// articleOrder 根据排序字段和方向（asc/desc，默认 desc）生成排序语句，字段无效时返回 fallback
+func articleOrder(sort string, order string, fallback string) string {
+	column, ok := articleSorts[sort]
+	if !ok {
+		return fallback
+	}
+	if order == "asc" {
+		return column + " ASC, article.id ASC"
+	}
+	return column + " DESC, article.id DESC"
+}
+
+// articleStatus 按状态筛选文章，status 为 0 时不筛选
+// 筛选已发布文章时按当前时间判断，定时任务延迟执行时也能正确展示和隐藏文章
+func articleStatus(status int) func(*gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		switch status {
+		case 0:
+			return tx
+		case ArticlePublished:
+			query, args := articleVisible(time.Now())
+			return tx.Where(query, args...)
+		}
+		return tx.Where("article.status = ?", status)
+	}
+}
+
+// GetCateArt 查询分类下的所有文章，status 指定文章状态（0 表示全部），sort 和 order 指定排序字段和方向
+func GetCateArt(ctx context.Context, id int, pageSize int, pageNum int, status int, sort string, order string) ([]Article, int, int64) {
 	var cateArtList []Article
 	var total int64
 
-	err := db.WithContext(ctx).Preload("Category").Limit(pageSize).Offset((pageNum-1)*pageSize).Where(
+	err := db.WithContext(ctx).Preload("Category").Preload("Tags").Omit("content_html", "toc").Scopes(articleStatus(status)).Order(articleOrder(sort, order, "")).Limit(pageSize).Offset((pageNum-1)*pageSize).Where(
 		"cid =?", id).Find(&cateArtList).Error
-	db.WithContext(ctx).Model(&cateArtList).Where("cid =?", id).Count(&total)
+	db.WithContext(ctx).Model(&cateArtList).Scopes(articleStatus(status)).Where("cid =?", id).Count(&total)
 	if err != nil {
 		return nil, errmsg.ErrorCateNotExist, 0
 	}
 	return cateArtList, errmsg.Success, total
 }
 
-// GetArtInfo 查询单个文章
-func GetArtInfo(ctx context.Context, id int) (Article, int) {
+// GetArtInfo 查询单个文章，status 指定文章状态（0 表示全部）
+// 只有按状态筛选的查询（前台访问）才计入阅读量
+func GetArtInfo(ctx context.Context, id int, status int) (Article, int) {
 	var art Article
-	err := db.WithContext(ctx).Where("id = ?", id).Preload("Category").First(&art).Error
-	db.WithContext(ctx).Model(&art).Where("id = ?", id).UpdateColumn("read_count", gorm.Expr("read_count + ?", 1))
+	err := db.WithContext(ctx).Where("id = ?", id).Scopes(articleStatus(status)).Preload("Category").Preload("Tags").First(&art).Error
 	if err != nil {
 		return art, errmsg.ErrorCateNotExist
 	}
+	if status != 0 {
+		db.WithContext(ctx).Model(&art).Where("id = ?", id).UpdateColumn("read_count", gorm.Expr("read_count + ?", 1))
+	}
+	// 历史文章没有保存渲染结果时实时渲染（历史文章均由管理员发布）
+	if art.ContentHtml == "" && art.Content != "" {
+		_ = art.renderContent(legacyAuthorRole)
+	}
 	return art, errmsg.Success
 }
 
-// GetArt 查询文章列表
-func GetArt(ctx context.Context, pageSize int, pageNum int) ([]Article, int, int64) {
+// GetArt 查询文章列表，status 指定文章状态（0 表示全部），sort 和 order 指定排序字段和方向
+func GetArt(ctx context.Context, pageSize int, pageNum int, status int, sort string, order string) ([]Article, int, int64) {
 	//var articleList []Article
 	//var err error
 	//var total int64
@@ -69,7 +311,7 @@ func GetArt(ctx context.Context, pageSize int, pageNum int) ([]Article, int, int
 	var cateArtList []Article
 	var total int64
 
-	err := db.WithContext(ctx).Preload("Category").Limit(pageSize).Offset((pageNum - 1) * pageSize).Find(&cateArtList).Count(&total).Error
+	err := db.WithContext(ctx).Preload("Category").Preload("Tags").Omit("content_html", "toc").Scopes(articleStatus(status)).Order(articleOrder(sort, order, "")).Limit(pageSize).Offset((pageNum - 1) * pageSize).Find(&cateArtList).Count(&total).Error
 	if err != nil {
 		return nil, errmsg.Error, 0
 	}
@@ -77,16 +319,16 @@ func GetArt(ctx context.Context, pageSize int, pageNum int) ([]Article, int, int
 
 }
 
-// SearchArticle 搜索文章标题
-func SearchArticle(ctx context.Context, title string, pageSize int, pageNum int) ([]Article, int, int64) {
+// SearchArticle 搜索文章标题，status 指定文章状态（0 表示全部），sort 和 order 指定排序字段和方向
+func SearchArticle(ctx context.Context, title string, pageSize int, pageNum int, status int, sort string, order string) ([]Article, int, int64) {
 	var articleList []Article
 	var err error
 	var total int64
-	err = db.WithContext(ctx).Select("article.id,title, img, created_at, updated_at, `desc`, comment_count, read_count, Category.name").Order("Created_At DESC").Joins("Category").Where("title LIKE ?",
+	err = db.WithContext(ctx).Preload("Tags").Select("article.id,title, img, thumb, created_at, updated_at, `desc`, comment_count, read_count, word_count, reading_time, slug, status, published_at, Category.name").Scopes(articleStatus(status)).Order(articleOrder(sort, order, "Created_At DESC")).Joins("Category").Where("title LIKE ?",
 		title+"%",
 	).Limit(pageSize).Offset((pageNum - 1) * pageSize).Find(&articleList).Error
 	//单独计数
-	db.WithContext(ctx).Model(&articleList).Where("title LIKE ?",
+	db.WithContext(ctx).Model(&articleList).Scopes(articleStatus(status)).Where("title LIKE ?",
 		title+"%",
 	).Count(&total)
 
@@ -96,23 +338,140 @@ func SearchArticle(ctx context.Context, title string, pageSize int, pageNum int)
 	return articleList, errmsg.Success, total
 }
 
-// EditArt 编辑文章
-func EditArt(ctx context.Context, id int, data *Article) int {
+// EditArt 编辑文章，渲染结果按编辑者角色清理，摘要转换为纯文本，每次编辑保存一个版本
+// 提交了新别名时修改别名，旧别名保留为历史别名；未提交别名时保持原别名
+// 文章被其他用户持有编辑锁时拒绝写入
+// data.Version 不为 0 时与文章当前版本比较，不一致说明文章已被他人修改，返回冲突错误码；保存成功后 data.Version 为新版本
+func EditArt(ctx context.Context, id int, data *Article, user User) int {
+	if code := checkTagNames(data.TagNames); code != errmsg.Success {
+		return code
+	}
 	var art Article
+	var current Article
+	db.WithContext(ctx).Select("id", "slug").Where("id = ?", id).First(&current)
+	if current.ID == 0 {
+		return errmsg.ErrorArtNotExist
+	}
+	newSlug := current.Slug
+	if data.Slug != "" || current.Slug == "" {
+		var code int
+		newSlug, code = resolveSlug(db.WithContext(ctx), current.ID, data.Slug, data.Title)
+		if code != errmsg.Success {
+			return code
+		}
+	}
+
 	var maps = make(map[string]interface{})
 	maps["title"] = data.Title
 	maps["cid"] = data.Cid
-	maps["desc"] = data.Desc
+	maps["desc"] = plainDesc(data.Desc)
 	maps["content"] = data.Content
 	maps["img"] = data.Img
 
-	err := db.WithContext(ctx).Model(&art).Where("id = ? ", id).Updates(&maps).Error
+	if err := data.renderContent(user.Role); err != nil {
+		return errmsg.Error
+	}
+	maps["content_html"] = data.ContentHtml
+	maps["toc"] = data.Toc
+	maps["word_count"] = data.WordCount
+	maps["reading_time"] = data.ReadingTime
+	maps["thumb"] = ThumbURL(ctx, data.Img)
+	maps["slug"] = newSlug
+	maps["version"] = gorm.Expr("version + 1")
+
+	code := errmsg.Success
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 锁定文章行，保证并发编辑时版本号连续；没有版本记录的历史文章先保存编辑前的内容
+		var locked Article
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&locked).Error; err != nil {
+			return err
+		}
+		if data.Version != 0 && data.Version != locked.Version {
+			code = errmsg.ErrorArtVersionConflict
+			return errVersionConflict
+		}
+		// 文章被其他用户锁定时拒绝写入
+		if err := checkLock(tx, locked.ID, user.ID); err != nil {
+			if errors.Is(err, errLockHeld) {
+				code = errmsg.ErrorArtLocked
+			}
+			return err
+		}
+		if err := ensureBaseRevision(tx, locked.ID); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&art).Where("id = ? ", id).Updates(&maps).Error; err != nil {
+			return err
+		}
+		if err := changeSlug(tx, current.ID, current.Slug, newSlug); err != nil {
+			return err
+		}
+		// 未提交标签列表时保持原有标签
+		if data.TagNames != nil {
+			tags, err := resolveTags(tx, data.TagNames)
+			if err != nil {
+				return err
+			}
+			art.ID = uint(id)
+			if len(tags) == 0 {
+				err = tx.Model(&art).Association("Tags").Clear()
+			} else {
+				err = tx.Model(&art).Association("Tags").Replace(tags)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		data.Version = locked.Version + 1
+		return createRevision(tx, uint(id), user.ID)
+	})
+	if code != errmsg.Success {
+		return code
+	}
 	if err != nil {
 		return errmsg.Error
 	}
 	return errmsg.Success
 }
 
+// PublishArt 发布文章，首次发布时记录发布时间
+func PublishArt(ctx context.Context, id int) int {
+	return setArticleStatus(ctx, id, ArticlePublished)
+}
+
+// UnpublishArt 撤回文章为草稿
+func UnpublishArt(ctx context.Context, id int) int {
+	return setArticleStatus(ctx, id, ArticleDraft)
+}
+
+// ArchiveArt 归档文章，归档后不在前台展示
+func ArchiveArt(ctx context.Context, id int) int {
+	return setArticleStatus(ctx, id, ArticleArchived)
+}
+
+// setArticleStatus 修改文章状态，不修改文章内容
+// 立即发布、撤回和归档都会取消定时发布；发布时清除已经过去的过期时间
+func setArticleStatus(ctx context.Context, id int, status int) int {
+	var art Article
+	db.WithContext(ctx).Select("id", "published_at", "expire_at").Where("id = ?", id).First(&art)
+	if art.ID == 0 {
+		return errmsg.ErrorArtNotExist
+	}
+	now := time.Now()
+	maps := map[string]interface{}{"status": status, "publish_at": nil}
+	if status == ArticlePublished && art.PublishedAt == nil {
+		maps["published_at"] = now
+	}
+	if status == ArticlePublished && art.ExpireAt != nil && !art.ExpireAt.After(now) {
+		maps["expire_at"] = nil
+	}
+	if err := db.WithContext(ctx).Model(&art).Updates(maps).Error; err != nil {
+		return errmsg.Error
+	}
+	return errmsg.Success
+}
+
 // DeleteArt 删除文章
 func DeleteArt(ctx context.Context, id int) int {
 	var art Article
@@ -0,0 +1,122 @@
+package model
+
+import (
+	"context"
+	"ginblog/utils"
+	"ginblog/utils/errmsg"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// ArticleAutosave 文章自动保存的工作副本，每个用户每篇文章只保留最新一份，不影响文章本身
+type ArticleAutosave struct {
+	ID        uint       `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `gorm:"index" json:"updated_at"`
+	ArticleId uint       `gorm:"type:int;not null;uniqueIndex:idx_autosave_user" json:"article_id"`
+	UserId    uint       `gorm:"type:int;not null;uniqueIndex:idx_autosave_user" json:"user_id"`
+	Title     string     `gorm:"type:varchar(100);not null" json:"title"`
+	Cid       int        `gorm:"type:int;not null" json:"cid"`
+	Desc      string     `gorm:"type:varchar(200)" json:"desc"`
+	Content   string     `gorm:"type:longtext" json:"content"`
+	Img       string     `gorm:"type:varchar(300)" json:"img"`
+	TagNames  StringList `gorm:"type:text" json:"tag_names"`
+	Version   int        `gorm:"type:int;not null;default:0" json:"version"` // 开始编辑时文章的版本，发布时用于检测冲突（0 表示不检查）
+}
+
+// SaveAutosave 保存当前用户对文章的工作副本，已有副本时覆盖
+// data.Version 为开始编辑时文章的版本；未提交时新副本使用文章当前版本，已有副本保留原来的版本
+func SaveAutosave(ctx context.Context, articleId int, data *ArticleAutosave, user User) int {
+	var art Article
+	db.WithContext(ctx).Select("id", "version").Where("id = ?", articleId).First(&art)
+	if art.ID == 0 {
+		return errmsg.ErrorArtNotExist
+	}
+	if data.Version < 0 || data.Version > art.Version {
+		return errmsg.ErrorArtVersionConflict
+	}
+	columns := []string{"updated_at", "title", "cid", "desc", "content", "img", "tag_names"}
+	if data.Version != 0 {
+		columns = append(columns, "version")
+	} else {
+		data.Version = art.Version
+	}
+	data.ID = 0
+	data.ArticleId = art.ID
+	data.UserId = user.ID
+	err := db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "article_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns(columns),
+	}).Create(data).Error
+	if err != nil {
+		return errmsg.Error
+	}
+	return errmsg.Success
+}
+
+// GetAutosave 查询当前用户对文章的最新工作副本
+func GetAutosave(ctx context.Context, articleId int, user User) (ArticleAutosave, int) {
+	var autosave ArticleAutosave
+	db.WithContext(ctx).Where("article_id = ? AND user_id = ?", articleId, user.ID).First(&autosave)
+	if autosave.ID == 0 {
+		return autosave, errmsg.ErrorArtAutosaveNotExist
+	}
+	return autosave, errmsg.Success
+}
+
+// DeleteAutosave 丢弃当前用户对文章的工作副本
+func DeleteAutosave(ctx context.Context, articleId int, user User) int {
+	err := db.WithContext(ctx).Where("article_id = ? AND user_id = ?", articleId, user.ID).Delete(&ArticleAutosave{}).Error
+	if err != nil {
+		return errmsg.Error
+	}
+	return errmsg.Success
+}
+
+// PublishAutosave 将工作副本的内容保存到文章（按普通编辑处理并记录版本），成功后删除工作副本
+// 只修改文章内容，不修改文章的发布状态；version 为期望的文章当前版本，为 0 时使用副本开始编辑时的版本，
+// 文章在此之后被他人修改时返回冲突错误码。成功时返回文章的新版本
+func PublishAutosave(ctx context.Context, articleId int, version int, user User) (int, int) {
+	autosave, code := GetAutosave(ctx, articleId, user)
+	if code != errmsg.Success {
+		return 0, code
+	}
+	if version == 0 {
+		version = autosave.Version
+	}
+	data := Article{
+		Title:    autosave.Title,
+		Cid:      autosave.Cid,
+		Desc:     autosave.Desc,
+		Content:  autosave.Content,
+		Img:      autosave.Img,
+		TagNames: append([]string{}, autosave.TagNames...),
+		Version:  version,
+	}
+	if code = EditArt(ctx, articleId, &data, user); code != errmsg.Success {
+		return 0, code
+	}
+	return data.Version, DeleteAutosave(ctx, articleId, user)
+}
+
+// CleanStaleAutosaves 删除超过保留时间未更新的工作副本，返回删除数量
+func CleanStaleAutosaves(ctx context.Context) (int64, error) {
+	result := db.WithContext(ctx).Where("updated_at < ?", time.Now().Add(-utils.AutosaveMaxAge)).Delete(&ArticleAutosave{})
+	return result.RowsAffected, result.Error
+}
+
+// StartAutosaveGC 启动过期工作副本的定时清理任务
+func StartAutosaveGC() {
+	interval := time.Hour
+	if utils.AutosaveMaxAge <= 0 {
+		interval = 0
+	}
+	runPeriodically("autosave-gc", interval, func(ctx context.Context) error {
+		removed, err := CleanStaleAutosaves(ctx)
+		if removed > 0 {
+			logrus.WithField("Task", "autosave-gc").Infof("已清理过期自动保存 %d 份", removed)
+		}
+		return err
+	})
+}
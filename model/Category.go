@@ -9,8 +9,9 @@ import (
 
 type Category struct {
 	gorm.Model
-	ID   uint   `gorm:"primary_key;auto_increment" json:"id"`
-	Name string `gorm:"type:varchar(20);not null" json:"name"`
+	ID      uint   `gorm:"primary_key;auto_increment" json:"id"`
+	Name    string `gorm:"type:varchar(20);not null" json:"name"`
+	Version int    `gorm:"type:int;not null;default:1" json:"version"` // 分类版本，每次编辑加 1，用于检测编辑冲突
 }
 
 // CheckCategory 查询分类是否存在
@@ -39,6 +40,7 @@ func CheckUpCategory(ctx context.Context, id int, name string) (code int) {
 
 // CreateCate 新增分类
 func CreateCate(ctx context.Context, data *Category) int {
+	data.Version = 1
 	err := db.WithContext(ctx).Create(&data).Error
 	if err != nil {
 		return errmsg.Error // 500
@@ -66,15 +68,28 @@ func GetCate(ctx context.Context, pageSize int, pageNum int) ([]Category, int64)
 }
 
 // EditCate 编辑分类信息
+// data.Version 不为 0 时只有版本一致才修改，不一致说明分类已被他人修改，返回冲突错误码
 func EditCate(ctx context.Context, id int, data *Category) int {
 	var cate Category
 	var maps = make(map[string]interface{})
 	maps["name"] = data.Name
+	maps["version"] = gorm.Expr("version + 1")
 
-	err := db.WithContext(ctx).Model(&cate).Where("id = ? ", id).Updates(maps).Error
-	if err != nil {
+	tx := db.WithContext(ctx).Model(&cate).Where("id = ? ", id)
+	if data.Version != 0 {
+		tx = tx.Where("version = ?", data.Version)
+	}
+	result := tx.Updates(maps)
+	if result.Error != nil {
 		return errmsg.Error
 	}
+	if result.RowsAffected == 0 {
+		db.WithContext(ctx).Select("id").Where("id = ?", id).First(&cate)
+		if cate.ID == 0 {
+			return errmsg.ErrorCateNotExist
+		}
+		return errmsg.ErrorCateVersionConflict
+	}
 	return errmsg.Success
 }
 
@@ -0,0 +1,369 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"ginblog/utils/errmsg"
+	"ginblog/utils/sanitize"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 评论状态
+const (
+	CommentApproved = 1 // 审核通过
+	CommentPending  = 2 // 待审核
+	CommentDeleted  = 3 // 已删除（保留占位以维持回复链）
+)
+
+// Comment 评论模型
+type Comment struct {
+	gorm.Model
+	UserId    uint   `gorm:"type:int;not null;index" json:"user_id"`
+	ArticleId uint   `gorm:"type:int;not null;index" json:"article_id"`
+	ParentId  uint   `gorm:"type:int;not null;default:0;index" json:"parent_id"`                         // 回复的评论ID，0 表示顶层评论
+	RootId    uint   `gorm:"type:int;not null;default:0;index" json:"root_id"`                           // 所属顶层评论ID，0 表示自身即顶层评论
+	Content   string `gorm:"type:text;not null" json:"content" validate:"required,max=500" label:"评论内容"` // 清理后的 HTML，转义后可能超过 500 字符
+	Status    int8   `gorm:"type:tinyint;not null;default:2" json:"status"`                              // 1-审核通过，2-待审核，3-已删除
+	Username  string `gorm:"->;-:migration" json:"username"`                                             // 评论用户名（关联查询）
+	Title     string `gorm:"->;-:migration" json:"title"`                                                // 所属文章标题（关联查询）
+}
+
+// commentSelect 评论列表关联查询字段
+const commentSelect = "comment.id, comment.created_at, comment.updated_at, comment.user_id, comment.article_id, " +
+	"comment.parent_id, comment.root_id, comment.content, comment.status, user.username, article.title"
+
+// CommentNode 评论树节点
+type CommentNode struct {
+	Comment
+	Depth    int            `json:"depth"`    // 嵌套层级，顶层评论为 0
+	Deleted  bool           `json:"deleted"`  // 是否为已删除的占位评论
+	Children []*CommentNode `json:"children"` // 子回复（按时间正序）
+}
+
+// AddComment 新增评论（默认待审核），评论者为当前登录用户
+func AddComment(ctx context.Context, data *Comment, user User) int {
+	data.UserId = user.ID
+	data.Content = sanitize.HTML(data.Content, sanitizePolicy(user.Role))
+	if !articlePublic(ctx, data.ArticleId) {
+		return errmsg.ErrorArtNotExist
+	}
+
+	// 回复评论时校验父评论，并记录所属顶层评论
+	data.RootId = 0
+	if data.ParentId != 0 {
+		var parent Comment
+		db.WithContext(ctx).Select("id, article_id, root_id, status").Where("id = ?", data.ParentId).First(&parent)
+		if parent.ID == 0 || parent.ArticleId != data.ArticleId || parent.Status == CommentDeleted {
+			return errmsg.ErrorCommentNotExist
+		}
+		data.RootId = parent.RootId
+		if data.RootId == 0 {
+			data.RootId = parent.ID
+		}
+	}
+
+	data.Status = CommentPending
+	err := db.WithContext(ctx).Create(&data).Error
+	if err != nil {
+		return errmsg.Error
+	}
+	return errmsg.Success
+}
+
+// GetComment 查询单个评论
+// front 为 true 时（前台接口）只返回已审核且所属文章在前台可见的评论
+func GetComment(ctx context.Context, id int, front bool) (Comment, int) {
+	var comment Comment
+	tx := db.WithContext(ctx).Select(commentSelect).
+		Joins("LEFT JOIN user ON user.id = comment.user_id").
+		Joins("LEFT JOIN article ON article.id = comment.article_id").
+		Where("comment.id = ?", id)
+	if front {
+		tx = tx.Where("comment.status = ?", CommentApproved)
+	}
+	err := tx.First(&comment).Error
+	if err != nil || front && !articlePublic(ctx, comment.ArticleId) {
+		return Comment{}, errmsg.ErrorCommentNotExist
+	}
+	return comment, errmsg.Success
+}
+
+// GetCommentList 后台查询评论列表（包含所有状态）
+func GetCommentList(ctx context.Context, pageSize int, pageNum int) ([]Comment, int64, int) {
+	var commentList []Comment
+	var total int64
+
+	db.WithContext(ctx).Model(&Comment{}).Count(&total)
+	err := db.WithContext(ctx).Select(commentSelect).
+		Joins("LEFT JOIN user ON user.id = comment.user_id").
+		Joins("LEFT JOIN article ON article.id = comment.article_id").
+		Order("comment.created_at DESC").
+		Limit(pageSize).Offset((pageNum - 1) * pageSize).Find(&commentList).Error
+	if err != nil {
+		return commentList, 0, errmsg.Error
+	}
+	return commentList, total, errmsg.Success
+}
+
+// articlePublic 文章是否在前台可见（已发布、未过期）
+func articlePublic(ctx context.Context, articleId uint) bool {
+	var art Article
+	db.WithContext(ctx).Select("id").Where("id = ?", articleId).Scopes(articleStatus(ArticlePublished)).First(&art)
+	return art.ID != 0
+}
+
+// GetCommentListFront 前台查询文章下已审核的评论，文章在前台不可见时返回文章不存在
+func GetCommentListFront(ctx context.Context, articleId int, pageSize int, pageNum int) ([]Comment, int64, int) {
+	var commentList []Comment
+	var total int64
+
+	if !articlePublic(ctx, uint(articleId)) {
+		return commentList, 0, errmsg.ErrorArtNotExist
+	}
+
+	db.WithContext(ctx).Model(&Comment{}).Where("article_id = ? AND status = ?", articleId, CommentApproved).Count(&total)
+	err := db.WithContext(ctx).Select(commentSelect).
+		Joins("LEFT JOIN user ON user.id = comment.user_id").
+		Joins("LEFT JOIN article ON article.id = comment.article_id").
+		Where("comment.article_id = ? AND comment.status = ?", articleId, CommentApproved).
+		Order("comment.created_at DESC").
+		Limit(pageSize).Offset((pageNum - 1) * pageSize).Find(&commentList).Error
+	if err != nil {
+		return commentList, 0, errmsg.Error
+	}
+	return commentList, total, errmsg.Success
+}
+
+// visibleCommentRoots 筛选文章下前台可见的顶层评论：已审核的评论，以及仍有已审核回复的已删除占位评论
+func visibleCommentRoots(articleId int) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("comment.article_id = ? AND comment.parent_id = 0", articleId).
+			Where("comment.status = ? OR comment.status = ? AND EXISTS (SELECT 1 FROM comment AS reply "+
+				"WHERE reply.root_id = comment.id AND reply.status = ? AND reply.deleted_at IS NULL)",
+				CommentApproved, CommentDeleted, CommentApproved)
+	}
+}
+
+// GetCommentTree 查询文章的评论树（按顶层评论分页）
+// 只返回已审核的评论；已删除但仍有可见回复的评论以占位形式保留；文章在前台不可见时返回文章不存在
+func GetCommentTree(ctx context.Context, articleId int, pageSize int, pageNum int) ([]*CommentNode, int64, int) {
+	visible := []int8{CommentApproved, CommentDeleted}
+	var roots []Comment
+	var total int64
+
+	if !articlePublic(ctx, uint(articleId)) {
+		return []*CommentNode{}, 0, errmsg.ErrorArtNotExist
+	}
+
+	db.WithContext(ctx).Model(&Comment{}).Scopes(visibleCommentRoots(articleId)).Count(&total)
+	err := db.WithContext(ctx).Select(commentSelect).
+		Joins("LEFT JOIN user ON user.id = comment.user_id").
+		Joins("LEFT JOIN article ON article.id = comment.article_id").
+		Scopes(visibleCommentRoots(articleId)).
+		Order("comment.created_at DESC").
+		Limit(pageSize).Offset((pageNum - 1) * pageSize).Find(&roots).Error
+	if err != nil {
+		return nil, 0, errmsg.Error
+	}
+	if len(roots) == 0 {
+		return []*CommentNode{}, total, errmsg.Success
+	}
+
+	rootIds := make([]uint, 0, len(roots))
+	for _, root := range roots {
+		rootIds = append(rootIds, root.ID)
+	}
+	var replies []Comment
+	err = db.WithContext(ctx).Select(commentSelect).
+		Joins("LEFT JOIN user ON user.id = comment.user_id").
+		Joins("LEFT JOIN article ON article.id = comment.article_id").
+		Where("comment.root_id IN ? AND comment.status IN ?", rootIds, visible).
+		Order("comment.created_at ASC").Find(&replies).Error
+	if err != nil {
+		return nil, 0, errmsg.Error
+	}
+
+	// 组装评论树：回复按时间正序，父评论一定早于子评论
+	nodes := make(map[uint]*CommentNode, len(roots)+len(replies))
+	tree := make([]*CommentNode, 0, len(roots))
+	for _, root := range roots {
+		node := newCommentNode(root, 0)
+		nodes[root.ID] = node
+		tree = append(tree, node)
+	}
+	for _, reply := range replies {
+		parent, ok := nodes[reply.ParentId]
+		if !ok {
+			// 父评论未审核或不可见，回复随之隐藏
+			continue
+		}
+		node := newCommentNode(reply, parent.Depth+1)
+		nodes[reply.ID] = node
+		parent.Children = append(parent.Children, node)
+	}
+
+	// 去除没有可见回复的占位评论
+	result := make([]*CommentNode, 0, len(tree))
+	for _, node := range tree {
+		if pruneCommentNode(node) {
+			result = append(result, node)
+		}
+	}
+	return result, total, errmsg.Success
+}
+
+// newCommentNode 创建评论树节点，已删除评论隐藏内容和作者
+func newCommentNode(comment Comment, depth int) *CommentNode {
+	node := &CommentNode{
+		Comment:  comment,
+		Depth:    depth,
+		Children: []*CommentNode{},
+	}
+	if comment.Status == CommentDeleted {
+		node.Deleted = true
+		node.Content = ""
+		node.UserId = 0
+		node.Username = ""
+	}
+	return node
+}
+
+// pruneCommentNode 递归去除没有可见回复的占位评论，返回该节点是否保留
+func pruneCommentNode(node *CommentNode) bool {
+	children := node.Children[:0]
+	for _, child := range node.Children {
+		if pruneCommentNode(child) {
+			children = append(children, child)
+		}
+	}
+	node.Children = children
+	return !node.Deleted || len(node.Children) > 0
+}
+
+// GetCommentCount 查询文章下已审核的评论数，文章在前台不可见时返回 0
+func GetCommentCount(ctx context.Context, articleId int) int64 {
+	var total int64
+	if !articlePublic(ctx, uint(articleId)) {
+		return 0
+	}
+	db.WithContext(ctx).Model(&Comment{}).Where("article_id = ? AND status = ?", articleId, CommentApproved).Count(&total)
+	return total
+}
+
+// DeleteComment 删除评论，已审核的评论同步扣减文章评论数
+// 有回复的评论仅清空内容并标记为已删除，以保持回复链完整
+func DeleteComment(ctx context.Context, id int) int {
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var comment Comment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&comment).Error; err != nil {
+			return err
+		}
+		if comment.Status == CommentDeleted {
+			return gorm.ErrRecordNotFound
+		}
+
+		var replies int64
+		if err := tx.Model(&Comment{}).Where("parent_id = ?", comment.ID).Count(&replies).Error; err != nil {
+			return err
+		}
+		if replies > 0 {
+			err := tx.Model(&comment).Updates(map[string]interface{}{
+				"status":  CommentDeleted,
+				"content": "",
+			}).Error
+			if err != nil {
+				return err
+			}
+		} else {
+			if err := tx.Delete(&comment).Error; err != nil {
+				return err
+			}
+			if err := pruneDeletedParent(tx, comment.ParentId); err != nil {
+				return err
+			}
+		}
+
+		if comment.Status == CommentApproved {
+			return changeCommentCount(tx, comment.ArticleId, -1)
+		}
+		return nil
+	})
+	return commentTxCode(err)
+}
+
+// pruneDeletedParent 回复被删除后，逐级清理不再有回复的占位评论
+func pruneDeletedParent(tx *gorm.DB, parentId uint) error {
+	for parentId != 0 {
+		var parent Comment
+		err := tx.Select("id, parent_id, status").Where("id = ?", parentId).First(&parent).Error
+		if err != nil || parent.Status != CommentDeleted {
+			return nil
+		}
+		var replies int64
+		if err := tx.Model(&Comment{}).Where("parent_id = ?", parent.ID).Count(&replies).Error; err != nil {
+			return err
+		}
+		if replies > 0 {
+			return nil
+		}
+		if err := tx.Delete(&parent).Error; err != nil {
+			return err
+		}
+		parentId = parent.ParentId
+	}
+	return nil
+}
+
+// CheckComment 审核通过评论
+func CheckComment(ctx context.Context, id int) int {
+	return setCommentStatus(ctx, id, CommentApproved)
+}
+
+// UncheckComment 撤下评论（恢复为待审核）
+func UncheckComment(ctx context.Context, id int) int {
+	return setCommentStatus(ctx, id, CommentPending)
+}
+
+// setCommentStatus 在事务中修改评论状态，并同步文章评论数
+func setCommentStatus(ctx context.Context, id int, status int8) int {
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var comment Comment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&comment).Error; err != nil {
+			return err
+		}
+		if comment.Status == CommentDeleted {
+			return gorm.ErrRecordNotFound
+		}
+		// 状态未变化时不重复计数
+		if comment.Status == status {
+			return nil
+		}
+		if err := tx.Model(&comment).Update("status", status).Error; err != nil {
+			return err
+		}
+		if status == CommentApproved {
+			return changeCommentCount(tx, comment.ArticleId, 1)
+		}
+		return changeCommentCount(tx, comment.ArticleId, -1)
+	})
+	return commentTxCode(err)
+}
+
+// changeCommentCount 调整文章评论数
+func changeCommentCount(tx *gorm.DB, articleId uint, delta int) error {
+	return tx.Model(&Article{}).Where("id = ?", articleId).
+		UpdateColumn("comment_count", gorm.Expr("GREATEST(comment_count + ?, 0)", delta)).Error
+}
+
+// commentTxCode 将评论事务错误转换为错误码
+func commentTxCode(err error) int {
+	switch {
+	case err == nil:
+		return errmsg.Success
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return errmsg.ErrorCommentNotExist
+	default:
+		return errmsg.Error
+	}
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVisibleCommentRoots(t *testing.T) {
+	tx := dryRunDB(t)
+	stmt := tx.Model(&Comment{}).Scopes(visibleCommentRoots(7)).Find(&[]Comment{}).Statement
+	sql := stmt.SQL.String()
+	want := "WHERE (comment.article_id = ? AND comment.parent_id = 0) AND (comment.status = ? OR comment.status = ? AND " +
+		"EXISTS (SELECT 1 FROM comment AS reply WHERE reply.root_id = comment.id AND reply.status = ? AND reply.deleted_at IS NULL))"
+	if !strings.Contains(sql, want) {
+		t.Errorf("SQL = %s\n期望包含 %s", sql, want)
+	}
+	if vars := stmt.Vars; len(vars) != 4 || vars[0] != 7 || vars[1] != CommentApproved || vars[2] != CommentDeleted || vars[3] != CommentApproved {
+		t.Errorf("Vars = %v", vars)
+	}
+}
+
+func TestPruneCommentNode(t *testing.T) {
+	deleted := func(children ...*CommentNode) *CommentNode {
+		return &CommentNode{Deleted: true, Children: children}
+	}
+	approved := func(children ...*CommentNode) *CommentNode {
+		return &CommentNode{Children: children}
+	}
+	tests := []struct {
+		name     string
+		node     *CommentNode
+		keep     bool
+		children int
+	}{
+		{"已审核评论保留", approved(), true, 0},
+		{"没有回复的占位评论去除", deleted(), false, 0},
+		{"只有占位回复的占位评论去除", deleted(deleted(), deleted(deleted())), false, 0},
+		{"有可见回复的占位评论保留", deleted(deleted(approved()), deleted()), true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if keep := pruneCommentNode(tt.node); keep != tt.keep || len(tt.node.Children) != tt.children {
+				t.Errorf("pruneCommentNode = %v, 子节点 %d 个, 期望 %v, %d 个", keep, len(tt.node.Children), tt.keep, tt.children)
+			}
+		})
+	}
+}
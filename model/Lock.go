@@ -0,0 +1,126 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"ginblog/utils"
+	"ginblog/utils/errmsg"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// ArticleLock 文章编辑锁（建议锁），每篇文章最多一个，超过有效期未续期自动失效
+type ArticleLock struct {
+	ArticleId uint      `gorm:"type:int;primaryKey;autoIncrement:false" json:"article_id"`
+	UserId    uint      `gorm:"type:int;not null;index" json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`                       // 获取锁的时间
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"` // 过期时间，每次续期延长
+	Username  string    `gorm:"->;-:migration" json:"username"`   // 持有者用户名（关联查询）
+}
+
+// errLockHeld 编辑锁被他人持有（用于中止事务）
+var errLockHeld = errors.New("article locked")
+
+// GetArticleLock 查询文章当前有效的编辑锁，没有时返回 nil
+func GetArticleLock(ctx context.Context, articleId int) *ArticleLock {
+	var lock ArticleLock
+	db.WithContext(ctx).Model(&ArticleLock{}).
+		Select("article_lock.*, user.username").
+		Joins("LEFT JOIN user ON user.id = article_lock.user_id").
+		Where("article_id = ? AND expires_at > ?", articleId, time.Now()).
+		Scan(&lock)
+	if lock.ArticleId == 0 {
+		return nil
+	}
+	return &lock
+}
+
+// AcquireLock 获取文章编辑锁
+// 锁不存在、已过期或已由本人持有时获取成功（本人持有时相当于续期）；被他人持有时返回冲突错误码和当前锁信息
+func AcquireLock(ctx context.Context, articleId int, user User) (*ArticleLock, int) {
+	return takeLock(ctx, articleId, user, false)
+}
+
+// ForceLock 强制获取文章编辑锁（管理员），无论锁是否被他人持有
+func ForceLock(ctx context.Context, articleId int, user User) (*ArticleLock, int) {
+	return takeLock(ctx, articleId, user, true)
+}
+
+// HeartbeatLock 续期本人持有的编辑锁；锁已被他人获取时返回错误
+// 锁已过期但尚未被他人获取时仍可续期
+func HeartbeatLock(ctx context.Context, articleId int, user User) (*ArticleLock, int) {
+	result := db.WithContext(ctx).Model(&ArticleLock{}).
+		Where("article_id = ? AND user_id = ?", articleId, user.ID).
+		Update("expires_at", time.Now().Add(utils.LockTTL))
+	if result.Error != nil {
+		return nil, errmsg.Error
+	}
+	if result.RowsAffected == 0 {
+		return GetArticleLock(ctx, articleId), errmsg.ErrorArtLockNotHeld
+	}
+	return GetArticleLock(ctx, articleId), errmsg.Success
+}
+
+// ReleaseLock 释放本人持有的编辑锁，未持有时也返回成功
+func ReleaseLock(ctx context.Context, articleId int, user User) int {
+	err := db.WithContext(ctx).Where("article_id = ? AND user_id = ?", articleId, user.ID).Delete(&ArticleLock{}).Error
+	if err != nil {
+		return errmsg.Error
+	}
+	return errmsg.Success
+}
+
+// takeLock 获取编辑锁，force 为 true 时忽略他人持有的锁
+func takeLock(ctx context.Context, articleId int, user User, force bool) (*ArticleLock, int) {
+	code := errmsg.Success
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 锁定文章行，避免并发获取同一篇文章的锁
+		var art Article
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", articleId).First(&art).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				code = errmsg.ErrorArtNotExist
+			}
+			return err
+		}
+		if !force {
+			if err := checkLock(tx, art.ID, user.ID); err != nil {
+				if errors.Is(err, errLockHeld) {
+					code = errmsg.ErrorArtLocked
+				}
+				return err
+			}
+		}
+
+		now := time.Now()
+		lock := ArticleLock{ArticleId: art.ID, UserId: user.ID, CreatedAt: now, ExpiresAt: now.Add(utils.LockTTL)}
+		var current ArticleLock
+		tx.Where("article_id = ?", art.ID).First(&current)
+		if current.ArticleId != 0 && current.UserId == user.ID && current.ExpiresAt.After(now) {
+			lock.CreatedAt = current.CreatedAt // 本人续期时保留获取时间
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "article_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"user_id", "created_at", "expires_at"}),
+		}).Create(&lock).Error
+	})
+	if code == errmsg.Success && err != nil {
+		code = errmsg.Error
+	}
+	return GetArticleLock(ctx, articleId), code
+}
+
+// checkLock 检查文章是否被其他用户锁定，在编辑文章的事务中调用
+func checkLock(tx *gorm.DB, articleId uint, userId uint) error {
+	var count int64
+	err := tx.Model(&ArticleLock{}).
+		Where("article_id = ? AND user_id <> ? AND expires_at > ?", articleId, userId, time.Now()).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return errLockHeld
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"ginblog/utils"
+	"ginblog/utils/errmsg"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+// Media 媒体库记录（每个上传到存储后端的文件对应一条记录）
+type Media struct {
+	gorm.Model
+	Key        string         `gorm:"type:varchar(200);not null;uniqueIndex" json:"key"` // 存储后端中的文件key
+	Url        string         `gorm:"type:varchar(300);not null" json:"url"`             // 文件访问URL
+	Size       int64          `gorm:"type:bigint;not null" json:"size"`                  // 文件大小（字节）
+	MimeType   string         `gorm:"type:varchar(100);not null" json:"mime_type"`       // 文件 MIME 类型
+	UserId     uint           `gorm:"type:int;not null;index" json:"user_id"`            // 上传者ID
+	Hash       string         `gorm:"type:char(64);not null;uniqueIndex" json:"hash"`    // 文件内容 SHA-256（相同内容只保存一份）
+	LastUsedAt *time.Time     `json:"last_used_at"`                                      // 最近一次上传（包括重复上传）的时间，清理宽限期从此时开始计算
+	Variants   []MediaVariant `json:"variants"`                                          // 图片缩略图
+}
+
+// MediaVariant 图片缩略图（按宽度缩放并重新编码的图片）
+type MediaVariant struct {
+	ID       uint   `gorm:"primarykey" json:"id"`
+	MediaId  uint   `gorm:"type:int;not null;index" json:"media_id"`
+	Width    int    `gorm:"type:int;not null" json:"width"`
+	Height   int    `gorm:"type:int;not null" json:"height"`
+	Key      string `gorm:"type:varchar(200);not null;uniqueIndex" json:"key"`
+	Url      string `gorm:"type:varchar(300);not null" json:"url"`
+	Size     int64  `gorm:"type:bigint;not null" json:"size"`
+	MimeType string `gorm:"type:varchar(100);not null" json:"mime_type"`
+}
+
+// CreateMedia 新增媒体记录
+func CreateMedia(ctx context.Context, data *Media) int {
+	if data.LastUsedAt == nil {
+		now := time.Now()
+		data.LastUsedAt = &now
+	}
+	err := db.WithContext(ctx).Create(&data).Error
+	if err != nil {
+		return errmsg.Error
+	}
+	return errmsg.Success
+}
+
+// GetMediaByHash 根据文件内容哈希查询媒体
+func GetMediaByHash(ctx context.Context, hash string) (Media, int) {
+	var media Media
+	db.WithContext(ctx).Preload("Variants", func(db *gorm.DB) *gorm.DB {
+		return db.Order("width ASC")
+	}).Where("hash = ?", hash).First(&media)
+	if media.ID == 0 {
+		return media, errmsg.ErrorMediaNotExist
+	}
+	return media, errmsg.Success
+}
+
+// reuseMedia 查询相同内容的已有媒体并刷新最近使用时间，避免刚返回给上传者的文件被清理任务删除
+func reuseMedia(ctx context.Context, hash string) (Media, int) {
+	media, code := GetMediaByHash(ctx, hash)
+	if code != errmsg.Success {
+		return media, code
+	}
+	now := time.Now()
+	if err := db.WithContext(ctx).Model(&media).UpdateColumn("last_used_at", now).Error; err != nil {
+		return media, errmsg.Error
+	}
+	media.LastUsedAt = &now
+	return media, errmsg.Success
+}
+
+// GetMediaList 查询媒体列表，keyword 非空时按文件key、URL或类型模糊搜索
+func GetMediaList(ctx context.Context, keyword string, pageSize int, pageNum int) ([]Media, int64, int) {
+	var mediaList []Media
+	var total int64
+
+	query := db.WithContext(ctx).Model(&Media{})
+	if keyword != "" {
+		like := "%" + likeEscape(keyword) + "%"
+		query = query.Where("`key` LIKE ? OR url LIKE ? OR mime_type LIKE ?", like, like, like)
+	}
+	query.Count(&total)
+	err := query.Preload("Variants").Order("id DESC").Limit(pageSize).Offset((pageNum - 1) * pageSize).Find(&mediaList).Error
+	if err != nil {
+		return nil, 0, errmsg.Error
+	}
+	return mediaList, total, errmsg.Success
+}
+
+// DeleteMedia 删除媒体（同时删除存储后端中的文件）
+func DeleteMedia(ctx context.Context, id int) int {
+	var media Media
+	err := db.WithContext(ctx).Preload("Variants").Where("id = ?", id).First(&media).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errmsg.ErrorMediaNotExist
+	}
+	if err != nil {
+		return errmsg.Error
+	}
+	if err = removeMedia(ctx, &media); err != nil {
+		return errmsg.Error
+	}
+	return errmsg.Success
+}
+
+// removeMedia 删除存储后端中的文件、缩略图及媒体记录
+// 记录直接物理删除，以便相同 key 的文件可以再次上传
+func removeMedia(ctx context.Context, media *Media) error {
+	for _, variant := range media.Variants {
+		if err := fileStore.Delete(ctx, variant.Key); err != nil {
+			return err
+		}
+	}
+	if err := fileStore.Delete(ctx, media.Key); err != nil {
+		return err
+	}
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("media_id = ?", media.ID).Delete(&MediaVariant{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(media).Error
+	})
+}
+
+// mediaReferenced 检查媒体（原图或任一缩略图）是否仍被文章封面、文章内容、文章历史版本、自动保存的工作副本、评论内容或用户头像引用
+func mediaReferenced(ctx context.Context, media *Media) (bool, error) {
+	urls := []string{media.Url}
+	for _, variant := range media.Variants {
+		urls = append(urls, variant.Url)
+	}
+	for _, url := range urls {
+		like := "%" + likeEscape(url) + "%"
+		queries := []*gorm.DB{
+			db.WithContext(ctx).Model(&Article{}).Where("img = ? OR content LIKE ?", url, like),
+			db.WithContext(ctx).Model(&ArticleRevision{}).Where("img = ? OR content LIKE ?", url, like),
+			db.WithContext(ctx).Model(&ArticleAutosave{}).Where("img = ? OR content LIKE ?", url, like),
+			db.WithContext(ctx).Model(&Comment{}).Where("content LIKE ?", like),
+			db.WithContext(ctx).Model(&Profile{}).Where("avatar = ?", url),
+		}
+		for _, query := range queries {
+			var count int64
+			if err := query.Count(&count).Error; err != nil || count > 0 {
+				return count > 0, err
+			}
+		}
+	}
+	return false, nil
+}
+
+// ThumbURL 返回图片URL对应的最小缩略图URL，没有缩略图时返回原URL
+func ThumbURL(ctx context.Context, url string) string {
+	if url == "" {
+		return ""
+	}
+	var variant MediaVariant
+	db.WithContext(ctx).Joins("JOIN media ON media.id = media_variant.media_id").
+		Where("media.url = ? AND media.deleted_at IS NULL", url).
+		Order("media_variant.width ASC").First(&variant)
+	if variant.ID == 0 {
+		return url
+	}
+	return variant.Url
+}
+
+// CleanUnusedMedia 清理最近使用时间超过宽限期且未被引用的媒体，返回清理数量
+func CleanUnusedMedia(ctx context.Context, grace time.Duration) (int, error) {
+	const batchSize = 100
+	before := time.Now().Add(-grace)
+	removed := 0
+	var lastId uint
+	for {
+		var batch []Media
+		err := db.WithContext(ctx).Preload("Variants").Where("id > ? AND COALESCE(last_used_at, created_at) < ?", lastId, before).
+			Order("id ASC").Limit(batchSize).Find(&batch).Error
+		if err != nil {
+			return removed, err
+		}
+		for i := range batch {
+			used, err := mediaReferenced(ctx, &batch[i])
+			if err != nil {
+				return removed, err
+			}
+			if used {
+				continue
+			}
+			if err = removeMedia(ctx, &batch[i]); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+		if len(batch) < batchSize {
+			return removed, nil
+		}
+		lastId = batch[len(batch)-1].ID
+	}
+}
+
+// StartMediaGC 启动未引用媒体的定时清理任务
+func StartMediaGC() {
+	runPeriodically("media-gc", utils.MediaGCInterval, func(ctx context.Context) error {
+		removed, err := CleanUnusedMedia(ctx, utils.MediaGracePeriod)
+		if removed > 0 {
+			logrus.WithField("Task", "media-gc").Infof("已清理未引用媒体 %d 个", removed)
+		}
+		return err
+	})
+}
+
+// likeEscape 转义 LIKE 查询中的通配符
+func likeEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,189 @@
+package model
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"ginblog/utils"
+	"ginblog/utils/errmsg"
+	"ginblog/utils/storage"
+	"github.com/sirupsen/logrus"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// PresignRequest 直传凭证申请参数
+type PresignRequest struct {
+	FileName    string `json:"file_name" validate:"required,max=200" label:"文件名"`
+	Size        int64  `json:"size" validate:"required,gt=0" label:"文件大小"`
+	ContentType string `json:"content_type" validate:"required" label:"文件类型"`
+}
+
+// PresignResult 直传凭证申请结果
+type PresignResult struct {
+	Upload storage.PresignedUpload `json:"upload"`
+	Token  string                  `json:"token"` // 上传完成后回调时提交的凭证
+}
+
+// presignClaims 回调凭证内容
+type presignClaims struct {
+	Key         string `json:"key"` // 暂存文件 key（随机命名）
+	Size        int64  `json:"size"`
+	ContentType string `json:"content_type"`
+	UserId      uint   `json:"user_id"`
+	ExpiresAt   int64  `json:"exp"`
+}
+
+// PresignUpload 生成浏览器直传凭证
+// 浏览器上传到随机命名的暂存 key，回调时由服务端校验内容后按服务端上传流程以内容哈希保存并去重
+// （哈希在清除元数据后计算，浏览器无法预先算出，因此申请凭证时不去重）；
+// 未回调的暂存文件需要在存储桶中为 presign/ 前缀配置过期规则清理
+func PresignUpload(ctx context.Context, req *PresignRequest, user User) (PresignResult, int) {
+	presigner, ok := fileStore.(storage.Presigner)
+	if !ok {
+		return PresignResult{}, errmsg.ErrorUploadPresignUnsupported
+	}
+	if req.Size > utils.UploadMaxSize {
+		return PresignResult{}, errmsg.ErrorUploadTooLarge
+	}
+	if !slices.Contains(utils.UploadAllowTypes, req.ContentType) {
+		return PresignResult{}, errmsg.ErrorUploadTypeNotAllowed
+	}
+	ext := strings.ToLower(filepath.Ext(req.FileName))
+	if !slices.Contains(uploadExts[req.ContentType], ext) {
+		return PresignResult{}, errmsg.ErrorUploadExtNotAllowed
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return PresignResult{}, errmsg.Error
+	}
+	claims := presignClaims{
+		Key:         "presign/" + hex.EncodeToString(id) + uploadExts[req.ContentType][0],
+		Size:        req.Size,
+		ContentType: req.ContentType,
+		UserId:      user.ID,
+		ExpiresAt:   time.Now().Add(utils.PresignExpire + time.Hour).Unix(), // 回调凭证多保留 1 小时，留出上传时间
+	}
+	upload, err := presigner.PresignPut(ctx, claims.Key, claims.Size, claims.ContentType, utils.PresignExpire)
+	if err != nil {
+		return PresignResult{}, errmsg.Error
+	}
+	token, err := signPresignClaims(claims)
+	if err != nil {
+		return PresignResult{}, errmsg.Error
+	}
+	return PresignResult{Upload: upload, Token: token}, errmsg.Success
+}
+
+// CompletePresignUpload 直传完成回调：校验凭证和暂存文件后记录到媒体库
+// 不信任浏览器声明的哈希和类型：暂存文件下载到服务端识别类型、计算哈希，
+// 再与服务端上传一样清除元数据、去重和生成缩略图，完成后删除暂存文件
+func CompletePresignUpload(ctx context.Context, token string, user User) (Media, int) {
+	presigner, ok := fileStore.(storage.Presigner)
+	if !ok {
+		return Media{}, errmsg.ErrorUploadPresignUnsupported
+	}
+	claims, ok := parsePresignClaims(token)
+	if !ok || claims.UserId != user.ID {
+		return Media{}, errmsg.ErrorUploadCallbackInvalid
+	}
+
+	// 存储中的文件必须存在，且大小和类型与申请凭证时声明的一致
+	info, err := presigner.Stat(ctx, claims.Key)
+	if err != nil || info.Size != claims.Size || info.ContentType != claims.ContentType {
+		return Media{}, errmsg.ErrorUploadCallbackInvalid
+	}
+	file, err := downloadPresignObject(ctx, presigner, claims)
+	if err != nil {
+		return Media{}, errmsg.ErrorUploadCallbackInvalid
+	}
+	defer func() {
+		file.Close()
+		os.Remove(file.Name())
+	}()
+
+	// 按内容识别的类型必须与声明的类型一致
+	contentType, code := CheckUploadFile(file, claims.Key, claims.Size, utils.UploadMaxSize)
+	if code != errmsg.Success {
+		return Media{}, code
+	}
+	if contentType != claims.ContentType {
+		return Media{}, errmsg.ErrorUploadCallbackInvalid
+	}
+	media, code := UpLoadFile(ctx, file, claims.Size, contentType, user)
+	if code != errmsg.Success {
+		return media, code
+	}
+
+	if err = fileStore.Delete(ctx, claims.Key); err != nil {
+		logrus.WithContext(ctx).WithField("Key", claims.Key).Warnf("删除直传暂存文件失败: %v", err)
+	}
+	return media, errmsg.Success
+}
+
+// downloadPresignObject 将暂存文件下载到本地临时文件，实际大小与声明不一致时返回错误
+func downloadPresignObject(ctx context.Context, presigner storage.Presigner, claims presignClaims) (*os.File, error) {
+	r, err := presigner.Open(ctx, claims.Key)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	file, err := os.CreateTemp("", "presign-*")
+	if err != nil {
+		return nil, err
+	}
+	n, err := io.Copy(file, io.LimitReader(r, claims.Size+1))
+	if err == nil && n != claims.Size {
+		err = errors.New("直传文件大小与声明不一致")
+	}
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, err
+	}
+	return file, nil
+}
+
+// signPresignClaims 生成回调凭证：base64(内容).HMAC-SHA256 签名（十六进制）
+func signPresignClaims(claims presignClaims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + presignSignature(encoded), nil
+}
+
+// parsePresignClaims 校验回调凭证的签名和有效期
+func parsePresignClaims(token string) (presignClaims, bool) {
+	var claims presignClaims
+	encoded, signature, found := strings.Cut(token, ".")
+	if !found || !hmac.Equal([]byte(signature), []byte(presignSignature(encoded))) {
+		return claims, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || json.Unmarshal(payload, &claims) != nil {
+		return claims, false
+	}
+	return claims, time.Now().Unix() <= claims.ExpiresAt
+}
+
+// presignSignature 使用 JWT 密钥计算签名
+func presignSignature(encoded string) string {
+	mac := hmac.New(sha256.New, []byte(utils.JwtKey))
+	mac.Write([]byte("presign:" + encoded))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"context"
+	"ginblog/utils/errmsg"
+	"gorm.io/gorm"
+)
+
+// Profile 用户个人资料（与 User 一对一关联）
+type Profile struct {
+	gorm.Model
+	UserId  uint   `gorm:"type:int;not null;uniqueIndex" json:"user_id"`
+	Name    string `gorm:"type:varchar(20)" json:"name" validate:"max=20" label:"昵称"`
+	Bio     string `gorm:"type:varchar(200)" json:"bio" validate:"max=200" label:"个人简介"`
+	Avatar  string `gorm:"type:varchar(200)" json:"avatar" validate:"omitempty,url,max=200" label:"头像"`
+	Email   string `gorm:"type:varchar(100)" json:"email" validate:"omitempty,email,max=100" label:"邮箱"`
+	Github  string `gorm:"type:varchar(200)" json:"github" validate:"omitempty,url,max=200" label:"GitHub"`
+	Weibo   string `gorm:"type:varchar(200)" json:"weibo" validate:"omitempty,url,max=200" label:"微博"`
+	Twitter string `gorm:"type:varchar(200)" json:"twitter" validate:"omitempty,url,max=200" label:"Twitter"`
+	Website string `gorm:"type:varchar(200)" json:"website" validate:"omitempty,url,max=200" label:"个人网站"`
+}
+
+// GetProfile 查询用户个人资料，尚未填写时返回空资料
+func GetProfile(ctx context.Context, userId int) (Profile, int) {
+	var user User
+	db.WithContext(ctx).Select("id").Where("id = ?", userId).First(&user)
+	if user.ID == 0 {
+		return Profile{}, errmsg.ErrorUserNotExist
+	}
+
+	var profile Profile
+	db.WithContext(ctx).Where("user_id = ?", userId).First(&profile)
+	profile.UserId = user.ID
+	return profile, errmsg.Success
+}
+
+// UpdateProfile 更新用户个人资料（不存在时创建）
+func UpdateProfile(ctx context.Context, userId int, data *Profile) int {
+	var user User
+	db.WithContext(ctx).Select("id").Where("id = ?", userId).First(&user)
+	if user.ID == 0 {
+		return errmsg.ErrorUserNotExist
+	}
+
+	var maps = make(map[string]interface{})
+	maps["name"] = data.Name
+	maps["bio"] = data.Bio
+	maps["avatar"] = data.Avatar
+	maps["email"] = data.Email
+	maps["github"] = data.Github
+	maps["weibo"] = data.Weibo
+	maps["twitter"] = data.Twitter
+	maps["website"] = data.Website
+
+	var profile Profile
+	err := db.WithContext(ctx).Where(Profile{UserId: user.ID}).Assign(maps).FirstOrCreate(&profile).Error
+	if err != nil {
+		return errmsg.Error
+	}
+	*data = profile
+	return errmsg.Success
+}
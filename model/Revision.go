@@ -0,0 +1,206 @@
+package model
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"ginblog/utils/errmsg"
+	"github.com/pmezard/go-difflib/difflib"
+	"gorm.io/gorm"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ArticleRevision 文章版本，每次保存文章时记录一份完整快照，创建后不再修改
+type ArticleRevision struct {
+	ID        uint       `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	ArticleId uint       `gorm:"type:int;not null;uniqueIndex:idx_revision_number" json:"article_id"`
+	Number    int        `gorm:"type:int;not null;uniqueIndex:idx_revision_number" json:"number"` // 文章内的版本号，从 1 开始递增
+	UserId    uint       `gorm:"type:int;not null;default:0" json:"user_id"`                      // 保存者，0 表示历史数据
+	Title     string     `gorm:"type:varchar(100);not null" json:"title"`
+	Cid       int        `gorm:"type:int;not null" json:"cid"`
+	Desc      string     `gorm:"type:varchar(200)" json:"desc"`
+	Content   string     `gorm:"type:longtext" json:"content,omitempty"`
+	Img       string     `gorm:"type:varchar(300)" json:"img"`
+	TagNames  StringList `gorm:"type:text" json:"tag_names"`
+	Username  string     `gorm:"->;-:migration" json:"username"` // 保存者用户名（关联查询）
+}
+
+// StringList 字符串列表，以 JSON 格式保存
+type StringList []string
+
+// Value 实现 driver.Valuer 接口
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(l)
+	return string(data), err
+}
+
+// Scan 实现 sql.Scanner 接口
+func (l *StringList) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("不支持的列表数据类型: %T", value)
+	}
+	if len(data) == 0 {
+		*l = nil
+		return nil
+	}
+	return json.Unmarshal(data, l)
+}
+
+// RevisionDiff 两个版本之间的差异
+type RevisionDiff struct {
+	From int    `json:"from"`
+	To   int    `json:"to"`
+	Diff string `json:"diff"` // unified diff 格式
+}
+
+// createRevision 保存文章当前内容为新版本
+func createRevision(tx *gorm.DB, articleId uint, userId uint) error {
+	var art Article
+	if err := tx.Preload("Tags").Where("id = ?", articleId).First(&art).Error; err != nil {
+		return err
+	}
+	var number int
+	if err := tx.Model(&ArticleRevision{}).Where("article_id = ?", articleId).
+		Select("COALESCE(MAX(number), 0)").Scan(&number).Error; err != nil {
+		return err
+	}
+	tagNames := make(StringList, 0, len(art.Tags))
+	for _, tag := range art.Tags {
+		tagNames = append(tagNames, tag.Name)
+	}
+	return tx.Create(&ArticleRevision{
+		ArticleId: articleId,
+		Number:    number + 1,
+		UserId:    userId,
+		Title:     art.Title,
+		Cid:       art.Cid,
+		Desc:      art.Desc,
+		Content:   art.Content,
+		Img:       art.Img,
+		TagNames:  tagNames,
+	}).Error
+}
+
+// ensureBaseRevision 没有版本记录的文章（增加版本功能前创建的文章）先保存当前内容为第一个版本
+func ensureBaseRevision(tx *gorm.DB, articleId uint) error {
+	var count int64
+	if err := tx.Model(&ArticleRevision{}).Where("article_id = ?", articleId).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return createRevision(tx, articleId, 0)
+}
+
+// GetRevisions 查询文章的版本列表（不含正文），按版本号倒序
+func GetRevisions(ctx context.Context, articleId int, pageSize int, pageNum int) ([]ArticleRevision, int64, int) {
+	var revisions []ArticleRevision
+	var total int64
+
+	db.WithContext(ctx).Model(&ArticleRevision{}).Where("article_id = ?", articleId).Count(&total)
+	err := db.WithContext(ctx).Model(&ArticleRevision{}).
+		Select("article_revision.id, article_revision.created_at, article_id, number, user_id, title, cid, `desc`, img, tag_names, user.username").
+		Joins("LEFT JOIN user ON user.id = article_revision.user_id").
+		Where("article_id = ?", articleId).
+		Order("number DESC").
+		Limit(pageSize).Offset((pageNum - 1) * pageSize).Scan(&revisions).Error
+	if err != nil {
+		return nil, 0, errmsg.Error
+	}
+	return revisions, total, errmsg.Success
+}
+
+// GetRevision 查询文章的指定版本（含正文）
+func GetRevision(ctx context.Context, articleId int, number int) (ArticleRevision, int) {
+	var revision ArticleRevision
+	db.WithContext(ctx).Model(&ArticleRevision{}).
+		Select("article_revision.*, user.username").
+		Joins("LEFT JOIN user ON user.id = article_revision.user_id").
+		Where("article_id = ? AND number = ?", articleId, number).
+		Scan(&revision)
+	if revision.ID == 0 {
+		return revision, errmsg.ErrorArtRevisionNotExist
+	}
+	return revision, errmsg.Success
+}
+
+// DiffRevisions 比较文章的两个版本，返回 unified diff
+func DiffRevisions(ctx context.Context, articleId int, from int, to int) (RevisionDiff, int) {
+	a, code := GetRevision(ctx, articleId, from)
+	if code != errmsg.Success {
+		return RevisionDiff{}, code
+	}
+	b, code := GetRevision(ctx, articleId, to)
+	if code != errmsg.Success {
+		return RevisionDiff{}, code
+	}
+	diff, err := diffSnapshots(&a, &b)
+	if err != nil {
+		return RevisionDiff{}, errmsg.Error
+	}
+	return RevisionDiff{From: from, To: to, Diff: diff}, errmsg.Success
+}
+
+// diffSnapshots 生成两个版本快照的 unified diff
+func diffSnapshots(a *ArticleRevision, b *ArticleRevision) (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(a.snapshot()),
+		B:        difflib.SplitLines(b.snapshot()),
+		FromFile: "revision-" + strconv.Itoa(a.Number),
+		ToFile:   "revision-" + strconv.Itoa(b.Number),
+		FromDate: a.CreatedAt.Format(time.RFC3339),
+		ToDate:   b.CreatedAt.Format(time.RFC3339),
+		Context:  3,
+	})
+}
+
+// RestoreRevision 将文章恢复为指定版本的内容，恢复结果作为新版本保存，不修改文章别名和状态
+// version 为期望的文章当前版本（0 表示不检查），与 EditArt 相同；成功时返回文章的新版本
+func RestoreRevision(ctx context.Context, articleId int, number int, version int, user User) (int, int) {
+	revision, code := GetRevision(ctx, articleId, number)
+	if code != errmsg.Success {
+		return 0, code
+	}
+	data := Article{
+		Title:    revision.Title,
+		Cid:      revision.Cid,
+		Desc:     revision.Desc,
+		Content:  revision.Content,
+		Img:      revision.Img,
+		TagNames: append([]string{}, revision.TagNames...),
+		Version:  version,
+	}
+	code = EditArt(ctx, articleId, &data, user)
+	return data.Version, code
+}
+
+// snapshot 版本内容的文本形式（元数据在前，正文在后），用于比较差异
+func (r *ArticleRevision) snapshot() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "标题: %s\n", r.Title)
+	fmt.Fprintf(&b, "分类: %d\n", r.Cid)
+	fmt.Fprintf(&b, "摘要: %s\n", r.Desc)
+	fmt.Fprintf(&b, "封面: %s\n", r.Img)
+	fmt.Fprintf(&b, "标签: %s\n", strings.Join(r.TagNames, ", "))
+	b.WriteString("\n")
+	b.WriteString(r.Content)
+	if !strings.HasSuffix(r.Content, "\n") {
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,127 @@
+package model
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStringListValue(t *testing.T) {
+	tests := []struct {
+		list StringList
+		want string
+	}{
+		{nil, "[]"},
+		{StringList{}, "[]"},
+		{StringList{"Go", "中文", `a"b`}, `["Go","中文","a\"b"]`},
+	}
+	for _, tt := range tests {
+		got, err := tt.list.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("Value(%#v) = %v, 期望 %s", tt.list, got, tt.want)
+		}
+	}
+}
+
+func TestStringListScan(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  StringList
+	}{
+		{"nil", nil, nil},
+		{"空字符串", "", nil},
+		{"空数组", "[]", StringList{}},
+		{"字节", []byte(`["a","b"]`), StringList{"a", "b"}},
+		{"字符串", `["中文"]`, StringList{"中文"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var list StringList
+			if err := list.Scan(tt.value); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(list, tt.want) {
+				t.Errorf("Scan() = %#v, 期望 %#v", list, tt.want)
+			}
+		})
+	}
+	var list StringList
+	if err := list.Scan(42); err == nil {
+		t.Error("不支持的类型应返回错误")
+	}
+	if err := list.Scan("{not json"); err == nil {
+		t.Error("无效的 JSON 应返回错误")
+	}
+}
+
+func TestStringListRoundTrip(t *testing.T) {
+	want := StringList{"Go", "Gin", "标签"}
+	value, err := want.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got StringList
+	if err = got.Scan(value); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("往返结果 %#v, 期望 %#v", got, want)
+	}
+}
+
+func TestRevisionSnapshot(t *testing.T) {
+	r := ArticleRevision{Title: "标题", Cid: 2, Desc: "摘要", Img: "a.png", TagNames: StringList{"Go", "Gin"}, Content: "正文"}
+	want := "标题: 标题\n分类: 2\n摘要: 摘要\n封面: a.png\n标签: Go, Gin\n\n正文\n"
+	if got := r.snapshot(); got != want {
+		t.Errorf("snapshot() = %q\n期望 %q", got, want)
+	}
+	// 正文已以换行结尾时不重复追加
+	r.Content = "正文\n"
+	if got := r.snapshot(); got != want {
+		t.Errorf("snapshot() = %q\n期望 %q", got, want)
+	}
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	a := ArticleRevision{Number: 1, CreatedAt: created, Title: "标题", Cid: 1, TagNames: StringList{"Go"}, Content: "第一行\n第二行\n"}
+	b := a
+	b.Number = 2
+	b.CreatedAt = created.Add(time.Hour)
+	b.TagNames = StringList{"Go", "Gin"}
+	b.Content = "第一行\n第二行（修改）\n"
+
+	diff, err := diffSnapshots(&a, &b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"--- revision-1\t2026-01-02T03:04:05Z\n",
+		"+++ revision-2\t2026-01-02T04:04:05Z\n",
+		"-标签: Go\n",
+		"+标签: Go, Gin\n",
+		" 第一行\n",
+		"-第二行\n",
+		"+第二行（修改）\n",
+	} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("diff 缺少 %q:\n%s", want, diff)
+		}
+	}
+	if strings.Contains(diff, "-标题") {
+		t.Errorf("未修改的行不应出现在差异中:\n%s", diff)
+	}
+
+	same, err := diffSnapshots(&a, &a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if same != "" {
+		t.Errorf("相同版本的 diff 应为空:\n%s", same)
+	}
+}
@@ -0,0 +1,102 @@
+package model
+
+import (
+	"context"
+	"ginblog/utils"
+	"ginblog/utils/errmsg"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"time"
+)
+
+// ScheduleForm 定时发布参数
+type ScheduleForm struct {
+	PublishAt *time.Time `json:"publish_at"` // 定时发布时间，为空时只修改过期时间
+	ExpireAt  *time.Time `json:"expire_at"`  // 过期时间，为空时取消过期
+}
+
+// articleVisible 前台可见文章的查询条件：已发布且未过期，或已到定时发布时间且未过期
+func articleVisible(now time.Time) (string, []interface{}) {
+	query := "((article.status = ? OR (article.status = ? AND article.publish_at <= ?)) AND (article.expire_at IS NULL OR article.expire_at > ?))"
+	return query, []interface{}{ArticlePublished, ArticleScheduled, now, now}
+}
+
+// validateSchedule 校验定时发布时间必须晚于当前时间，过期时间必须晚于发布时间（未定时发布时晚于当前时间）
+func validateSchedule(publishAt *time.Time, expireAt *time.Time) int {
+	now := time.Now()
+	if publishAt != nil && !publishAt.After(now) {
+		return errmsg.ErrorArtScheduleInvalid
+	}
+	if expireAt != nil {
+		start := now
+		if publishAt != nil {
+			start = *publishAt
+		}
+		if !expireAt.After(start) {
+			return errmsg.ErrorArtScheduleInvalid
+		}
+	}
+	return errmsg.Success
+}
+
+// ScheduleArt 设置文章的定时发布时间和过期时间
+// 提交了发布时间时文章转为定时发布；未提交时只修改过期时间，文章状态不变
+func ScheduleArt(ctx context.Context, id int, data *ScheduleForm) int {
+	var art Article
+	db.WithContext(ctx).Select("id", "status", "publish_at").Where("id = ?", id).First(&art)
+	if art.ID == 0 {
+		return errmsg.ErrorArtNotExist
+	}
+	publishAt := data.PublishAt
+	if publishAt == nil && art.Status == ArticleScheduled {
+		publishAt = art.PublishAt
+	}
+	if code := validateSchedule(data.PublishAt, data.ExpireAt); code != errmsg.Success {
+		return code
+	}
+	if publishAt != nil && data.ExpireAt != nil && !data.ExpireAt.After(*publishAt) {
+		return errmsg.ErrorArtScheduleInvalid
+	}
+
+	maps := map[string]interface{}{"expire_at": data.ExpireAt}
+	if data.PublishAt != nil {
+		maps["status"] = ArticleScheduled
+		maps["publish_at"] = data.PublishAt
+	}
+	if err := db.WithContext(ctx).Model(&art).Updates(maps).Error; err != nil {
+		return errmsg.Error
+	}
+	return errmsg.Success
+}
+
+// RunArticleSchedule 发布已到时间的定时文章，归档已过期的文章，返回发布和归档的数量
+func RunArticleSchedule(ctx context.Context) (int64, int64, error) {
+	now := time.Now()
+	published := db.WithContext(ctx).Model(&Article{}).
+		Where("status = ? AND publish_at <= ?", ArticleScheduled, now).
+		UpdateColumns(map[string]interface{}{
+			"status":       ArticlePublished,
+			"published_at": gorm.Expr("COALESCE(published_at, publish_at)"),
+		})
+	if published.Error != nil {
+		return 0, 0, published.Error
+	}
+	archived := db.WithContext(ctx).Model(&Article{}).
+		Where("status = ? AND expire_at <= ?", ArticlePublished, now).
+		UpdateColumns(map[string]interface{}{
+			"status":    ArticleArchived,
+			"expire_at": nil,
+		})
+	return published.RowsAffected, archived.RowsAffected, archived.Error
+}
+
+// StartArticleScheduler 启动定时发布和过期归档任务
+func StartArticleScheduler() {
+	runPeriodically("article-scheduler", utils.ScheduleInterval, func(ctx context.Context) error {
+		published, archived, err := RunArticleSchedule(ctx)
+		if published > 0 || archived > 0 {
+			logrus.WithField("Task", "article-scheduler").Infof("已发布定时文章 %d 篇，已归档过期文章 %d 篇", published, archived)
+		}
+		return err
+	})
+}
@@ -0,0 +1,98 @@
+package model
+
+import (
+	"ginblog/utils/errmsg"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"strings"
+	"testing"
+	"time"
+)
+
+// dryRunDB 只生成 SQL、不连接数据库的 gorm 实例
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	tx, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "test:test@tcp(127.0.0.1:3306)/test?parseTime=True",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+		NamingStrategy:       schema.NamingStrategy{SingularTable: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func TestValidateSchedule(t *testing.T) {
+	now := time.Now()
+	at := func(d time.Duration) *time.Time {
+		v := now.Add(d)
+		return &v
+	}
+	tests := []struct {
+		name      string
+		publishAt *time.Time
+		expireAt  *time.Time
+		want      int
+	}{
+		{"都为空", nil, nil, errmsg.Success},
+		{"将来发布", at(time.Hour), nil, errmsg.Success},
+		{"过去发布", at(-time.Hour), nil, errmsg.ErrorArtScheduleInvalid},
+		{"将来过期", nil, at(time.Hour), errmsg.Success},
+		{"过去过期", nil, at(-time.Hour), errmsg.ErrorArtScheduleInvalid},
+		{"过期晚于发布", at(time.Hour), at(2 * time.Hour), errmsg.Success},
+		{"过期早于发布", at(2 * time.Hour), at(time.Hour), errmsg.ErrorArtScheduleInvalid},
+		{"过期等于发布", at(time.Hour), at(time.Hour), errmsg.ErrorArtScheduleInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validateSchedule(tt.publishAt, tt.expireAt); got != tt.want {
+				t.Errorf("validateSchedule() = %d, 期望 %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArticleVisible(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	query, args := articleVisible(now)
+	if got, want := strings.Count(query, "?"), len(args); got != want {
+		t.Fatalf("占位符 %d 个，参数 %d 个", got, want)
+	}
+	want := []interface{}{ArticlePublished, ArticleScheduled, now, now}
+	for i := range want {
+		if args[i] != want[i] {
+			t.Errorf("第 %d 个参数 = %v, 期望 %v", i+1, args[i], want[i])
+		}
+	}
+	for _, part := range []string{"article.status = ?", "article.publish_at <= ?", "article.expire_at IS NULL", "article.expire_at > ?"} {
+		if !strings.Contains(query, part) {
+			t.Errorf("查询条件缺少 %q: %s", part, query)
+		}
+	}
+}
+
+func TestArticleStatusScope(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		want   string
+	}{
+		{"全部", 0, "SELECT * FROM `article` WHERE `article`.`deleted_at` IS NULL"},
+		{"草稿", ArticleDraft, "WHERE article.status = ? AND `article`.`deleted_at` IS NULL"},
+		{"已发布", ArticlePublished, "WHERE (((article.status = ? OR (article.status = ? AND article.publish_at <= ?)) AND (article.expire_at IS NULL OR article.expire_at > ?))) AND `article`.`deleted_at` IS NULL"},
+	}
+	tx := dryRunDB(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt := tx.Scopes(articleStatus(tt.status)).Find(&[]Article{}).Statement
+			if sql := stmt.SQL.String(); !strings.Contains(sql, tt.want) {
+				t.Errorf("SQL = %s\n期望包含 %s", sql, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package model
+
+import (
+	"context"
+	"ginblog/utils/errmsg"
+	"ginblog/utils/slug"
+	"gorm.io/gorm"
+	"strconv"
+	"time"
+)
+
+// SlugHistory 文章的历史别名，按历史别名访问时重定向到当前别名
+type SlugHistory struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	ArticleId uint      `gorm:"type:int;not null;index" json:"article_id"`
+	Slug      string    `gorm:"type:varchar(100);not null;uniqueIndex" json:"slug"`
+}
+
+// GetArtBySlug 按别名查询文章，status 指定文章状态（0 表示全部）
+// 别名是某篇文章的历史别名时不返回文章，而是返回该文章的当前别名，由调用方重定向；
+// 文章状态不符时按文章不存在处理，不泄露未发布文章的当前别名
+func GetArtBySlug(ctx context.Context, s string, status int) (Article, string, int) {
+	var art Article
+	db.WithContext(ctx).Select("id").Where("slug = ?", s).First(&art)
+	if art.ID != 0 {
+		art, code := GetArtInfo(ctx, int(art.ID), status)
+		return art, "", code
+	}
+
+	var history SlugHistory
+	db.WithContext(ctx).Where("slug = ?", s).First(&history)
+	if history.ID == 0 {
+		return art, "", errmsg.ErrorArtNotExist
+	}
+	db.WithContext(ctx).Select("id", "slug").Where("id = ?", history.ArticleId).Scopes(articleStatus(status)).First(&art)
+	if art.ID == 0 || art.Slug == "" {
+		return Article{}, "", errmsg.ErrorArtNotExist
+	}
+	return Article{}, art.Slug, errmsg.Success
+}
+
+// resolveSlug 确定文章要使用的别名
+// 手动设置的别名必须格式正确且未被其他文章占用；未设置时根据标题生成，重复时依次追加 "-2"、"-3"
+func resolveSlug(tx *gorm.DB, articleId uint, manual string, title string) (string, int) {
+	if manual != "" {
+		manual = slug.Normalize(manual)
+		if !slug.Valid(manual) {
+			return "", errmsg.ErrorArtSlugInvalid
+		}
+		if slugTaken(tx, articleId, manual) {
+			return "", errmsg.ErrorArtSlugUsed
+		}
+		return manual, errmsg.Success
+	}
+
+	base := slug.Make(title)
+	if base == "" {
+		base = "article"
+	}
+	s := base
+	for i := 2; slugTaken(tx, articleId, s); i++ {
+		suffix := "-" + strconv.Itoa(i)
+		s = slug.Truncate(base[:min(len(base), slug.MaxLen-len(suffix))]) + suffix
+	}
+	return s, errmsg.Success
+}
+
+// slugTaken 别名是否已被其他文章（包括已删除文章和其他文章的历史别名）占用
+func slugTaken(tx *gorm.DB, articleId uint, s string) bool {
+	var count int64
+	tx.Model(&Article{}).Unscoped().Where("slug = ? AND id <> ?", s, articleId).Count(&count)
+	if count > 0 {
+		return true
+	}
+	tx.Model(&SlugHistory{}).Where("slug = ? AND article_id <> ?", s, articleId).Count(&count)
+	return count > 0
+}
+
+// changeSlug 修改文章别名，旧别名记入历史；新别名曾是本文章的历史别名时从历史中移除
+func changeSlug(tx *gorm.DB, articleId uint, oldSlug string, newSlug string) error {
+	if oldSlug == newSlug {
+		return nil
+	}
+	if err := tx.Where("article_id = ? AND slug = ?", articleId, newSlug).Delete(&SlugHistory{}).Error; err != nil {
+		return err
+	}
+	if oldSlug == "" {
+		return nil
+	}
+	return tx.Create(&SlugHistory{ArticleId: articleId, Slug: oldSlug}).Error
+}
+
+// BackfillArticleSlugs 为没有别名的历史文章根据标题生成别名，返回处理数量
+func BackfillArticleSlugs(ctx context.Context) (int, error) {
+	var batch []Article
+	count := 0
+	err := db.WithContext(ctx).Unscoped().Select("id", "title").
+		Where("slug IS NULL OR slug = ''").
+		FindInBatches(&batch, 100, func(tx *gorm.DB, _ int) error {
+			for i := range batch {
+				art := &batch[i]
+				s, _ := resolveSlug(db.WithContext(ctx), art.ID, "", art.Title)
+				err := db.WithContext(ctx).Unscoped().Model(art).UpdateColumn("slug", s).Error
+				if err != nil {
+					return err
+				}
+				count++
+			}
+			return nil
+		}).Error
+	return count, err
+}
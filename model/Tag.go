@@ -0,0 +1,115 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"ginblog/utils/errmsg"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Tag 文章标签（与文章多对多关联，名称不区分大小写去重）
+type Tag struct {
+	gorm.Model
+	Name string `gorm:"type:varchar(30) COLLATE utf8mb4_general_ci;not null;uniqueIndex" json:"name"` // 不区分大小写的唯一索引
+}
+
+// TagCount 标签及其关联的文章数
+type TagCount struct {
+	ID           uint   `json:"id"`
+	Name         string `json:"name"`
+	ArticleCount int64  `json:"article_count"`
+}
+
+// tagNameMaxLen 标签名的最大字符数（与 Name 字段长度一致）
+const tagNameMaxLen = 30
+
+// checkTagNames 检查标签名长度，在写入文章前调用，避免超长标签名使整个保存事务失败
+func checkTagNames(names []string) int {
+	for _, name := range names {
+		if utf8.RuneCountInString(strings.TrimSpace(name)) > tagNameMaxLen {
+			return errmsg.ErrorTagNameTooLong
+		}
+	}
+	return errmsg.Success
+}
+
+// resolveTags 根据标签名查找标签，不存在时创建
+// 标签名去除首尾空白后按小写去重，保留首次出现的写法
+func resolveTags(tx *gorm.DB, names []string) ([]Tag, error) {
+	tags := make([]Tag, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		key := strings.ToLower(name)
+		if name == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		var tag Tag
+		err := tx.Where("LOWER(name) = ?", key).First(&tag).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			tag = Tag{Name: name}
+			err = tx.Create(&tag).Error
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				// 并发保存时标签已由其他事务创建，使用加锁读取查询最新提交的标签
+				tag = Tag{}
+				err = tx.Clauses(clause.Locking{Strength: "SHARE"}).Where("LOWER(name) = ?", key).First(&tag).Error
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// GetTags 查询标签列表及每个标签下已发布的文章数
+func GetTags(ctx context.Context, pageSize int, pageNum int) ([]TagCount, int64, int) {
+	var tags []TagCount
+	var total int64
+
+	db.WithContext(ctx).Model(&Tag{}).Count(&total)
+	visible, args := articleVisible(time.Now())
+	err := db.WithContext(ctx).Model(&Tag{}).
+		Select("tag.id, tag.name, COUNT(article.id) AS article_count").
+		Joins("LEFT JOIN article_tag ON article_tag.tag_id = tag.id").
+		Joins("LEFT JOIN article ON article.id = article_tag.article_id AND article.deleted_at IS NULL AND "+visible, args...).
+		Group("tag.id, tag.name").
+		Order("article_count DESC, tag.id ASC").
+		Limit(pageSize).Offset((pageNum - 1) * pageSize).Scan(&tags).Error
+	if err != nil {
+		return nil, 0, errmsg.Error
+	}
+	return tags, total, errmsg.Success
+}
+
+// GetTagArt 查询标签下的所有文章，status 指定文章状态（0 表示全部），sort 和 order 指定排序字段和方向
+func GetTagArt(ctx context.Context, id int, pageSize int, pageNum int, status int, sort string, order string) ([]Article, int, int64) {
+	var tag Tag
+	db.WithContext(ctx).Select("id").Where("id = ?", id).First(&tag)
+	if tag.ID == 0 {
+		return nil, errmsg.ErrorTagNotExist, 0
+	}
+
+	var tagArtList []Article
+	var total int64
+
+	db.WithContext(ctx).Model(&Article{}).
+		Joins("JOIN article_tag ON article_tag.article_id = article.id").
+		Where("article_tag.tag_id = ?", id).Scopes(articleStatus(status)).Count(&total)
+	err := db.WithContext(ctx).Preload("Category").Preload("Tags").Omit("content_html", "toc").
+		Joins("JOIN article_tag ON article_tag.article_id = article.id").
+		Where("article_tag.tag_id = ?", id).Scopes(articleStatus(status)).
+		Order(articleOrder(sort, order, "article.created_at DESC")).
+		Limit(pageSize).Offset((pageNum - 1) * pageSize).Find(&tagArtList).Error
+	if err != nil {
+		return nil, errmsg.Error, 0
+	}
+	return tagArtList, errmsg.Success, total
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"ginblog/utils/errmsg"
+	"strings"
+	"testing"
+)
+
+func TestCheckTagNames(t *testing.T) {
+	tests := []struct {
+		name  string
+		names []string
+		want  int
+	}{
+		{"空列表", nil, errmsg.Success},
+		{"普通标签", []string{"Go", "数据库"}, errmsg.Success},
+		{"30 个中文字符", []string{strings.Repeat("标", 30)}, errmsg.Success},
+		{"首尾空白不计入", []string{" " + strings.Repeat("a", 30) + " "}, errmsg.Success},
+		{"31 个中文字符", []string{"Go", strings.Repeat("标", 31)}, errmsg.ErrorTagNameTooLong},
+		{"31 个字母", []string{strings.Repeat("a", 31)}, errmsg.ErrorTagNameTooLong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkTagNames(tt.names); got != tt.want {
+				t.Errorf("checkTagNames(%q) = %d, 期望 %d", tt.names, got, tt.want)
+			}
+		})
+	}
+}
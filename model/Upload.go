@@ -2,85 +2,223 @@
 package model
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"ginblog/utils"
 	"ginblog/utils/errmsg"
-	"github.com/qiniu/go-sdk/v7/auth/qbox"
-	"github.com/qiniu/go-sdk/v7/storage"
-	"mime/multipart"
+	"ginblog/utils/imageutil"
+	"ginblog/utils/storage"
+	"github.com/sirupsen/logrus"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
 )
 
-// 七牛云配置全局变量
-var (
-	Zone      = utils.Zone       // 存储区域编号（1-华东，2-华北，3-华南）
-	AccessKey = utils.AccessKey  // 七牛云AccessKey
-	SecretKey = utils.SecretKey  // 七牛云SecretKey
-	Bucket    = utils.Bucket     // 存储空间名称
-	ImgUrl    = utils.QiniuSever // 七牛云图片访问域名
-)
+// fileStore 文件存储后端（由 InitStorage 根据配置创建）
+var fileStore storage.Storage
+
+// uploadExts 各文件类型允许的扩展名，第一个为保存时使用的扩展名
+var uploadExts = map[string][]string{
+	"image/jpeg":      {".jpg", ".jpeg"},
+	"image/png":       {".png"},
+	"image/gif":       {".gif"},
+	"image/webp":      {".webp"},
+	"image/bmp":       {".bmp"},
+	"application/pdf": {".pdf"},
+	"application/zip": {".zip", ".docx", ".xlsx", ".pptx"},
+	"text/plain":      {".txt", ".md"},
+}
+
+// variantTypes 需要清除元数据和生成缩略图的图片类型
+var variantTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+}
+
+// InitStorage 根据配置初始化文件存储后端
+func InitStorage() {
+	var err error
+	fileStore, err = storage.New()
+	if err != nil {
+		log.Fatal("文件存储初始化失败: ", err)
+	}
+}
 
-// UpLoadFile 上传文件到七牛云存储
+// CheckUploadFile 校验上传文件的大小、真实类型和扩展名
 // 参数：
 //
 //	file - 要上传的文件对象
+//	fileName - 原始文件名（用于校验扩展名）
 //	fileSize - 文件大小（字节）
+//	maxSize - 允许的最大文件大小（字节）
 //
 // 返回值：
 //
-//	string - 文件访问URL
+//	string - 根据文件内容识别出的 MIME 类型
 //	int - 状态错误码
-func UpLoadFile(file multipart.File, fileSize int64) (string, int) {
-	// 创建上传策略
-	putPolicy := storage.PutPolicy{
-		Scope: Bucket, // 指定目标存储空间
-	}
-	// 创建Mac对象用于签名
-	mac := qbox.NewMac(AccessKey, SecretKey)
-	// 生成上传凭证
-	upToken := putPolicy.UploadToken(mac)
-
-	// 获取存储配置
-	cfg := setConfig()
-
-	// 创建表单上传对象
-	formUploader := storage.NewFormUploader(&cfg)
-	// 初始化返回结构
-	ret := storage.PutRet{}
-	// 不需要额外参数
-	putExtra := storage.PutExtra{}
-
-	// 执行上传操作（不指定存储文件名）
-	err := formUploader.PutWithoutKey(context.Background(), &ret, upToken, file, fileSize, &putExtra)
-	if err != nil {
-		return "", errmsg.Error // 返回上传错误
+func CheckUploadFile(file io.ReadSeeker, fileName string, fileSize int64, maxSize int64) (string, int) {
+	if fileSize > maxSize {
+		return "", errmsg.ErrorUploadTooLarge
+	}
+
+	// 读取文件头部字节识别真实类型，读取后回到文件开头
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", errmsg.Error
 	}
-	// 拼接完整访问URL
-	url := ImgUrl + ret.Key
-	return url, errmsg.Success
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return "", errmsg.Error
+	}
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(head[:n]))
+	if err != nil || !slices.Contains(utils.UploadAllowTypes, contentType) {
+		return "", errmsg.ErrorUploadTypeNotAllowed
+	}
+
+	// 扩展名必须与识别出的类型相符，防止伪装文件
+	ext := strings.ToLower(filepath.Ext(fileName))
+	if !slices.Contains(uploadExts[contentType], ext) {
+		return "", errmsg.ErrorUploadExtNotAllowed
+	}
+	return contentType, errmsg.Success
 }
 
-// setConfig 配置七牛云存储区域
-// 返回值：storage.Config - 存储配置对象
-func setConfig() storage.Config {
-	return storage.Config{
-		Zone:          selectZone(Zone), // 根据配置选择存储区域
-		UseCdnDomains: false,            // 不使用CDN域名
-		UseHTTPS:      false,            // 不使用HTTPS
+// UpLoadFile 上传文件到存储后端，并记录到媒体库
+// 文件以内容的 SHA-256 作为 key，相同内容的文件直接返回已有记录，不重复写入存储
+// 参数：
+//
+//	ctx - 请求上下文
+//	file - 要上传的文件对象
+//	fileSize - 文件大小（字节）
+//	contentType - 文件的 MIME 类型
+//	user - 上传者
+//
+// 返回值：
+//
+//	Media - 媒体库记录（包含文件访问URL）
+//	int - 状态错误码
+func UpLoadFile(ctx context.Context, file io.ReadSeeker, fileSize int64, contentType string, user User) (Media, int) {
+	// 图片需要整体读入内存处理，限制大小防止耗尽内存
+	if variantTypes[contentType] && fileSize > utils.ImageMaxSize {
+		return Media{}, errmsg.ErrorUploadTooLarge
+	}
+	// 清除图片元数据（GPS坐标、相机信息等），按配置对指定角色保留
+	if utils.StripMeta && variantTypes[contentType] && !slices.Contains(utils.KeepMetaRoles, user.Role) {
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return Media{}, errmsg.Error
+		}
+		data, err = imageutil.StripMetadata(data, strings.TrimPrefix(contentType, "image/"), utils.ImageQuality)
+		if err != nil {
+			return Media{}, errmsg.ErrorUploadBadImage
+		}
+		file, fileSize = bytes.NewReader(data), int64(len(data))
+	}
+
+	// 计算文件内容哈希，计算后回到文件开头
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return Media{}, errmsg.Error
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return Media{}, errmsg.Error
+	}
+	sum := hex.EncodeToString(hash.Sum(nil))
+
+	// 相同内容已上传过，直接返回已有记录
+	if media, code := reuseMedia(ctx, sum); code == errmsg.Success {
+		return media, errmsg.Success
+	}
+
+	// 以内容哈希作为文件名，扩展名与文件类型一致
+	key := sum
+	if exts := uploadExts[contentType]; len(exts) > 0 {
+		key += exts[0]
+	}
+
+	// 执行上传操作
+	url, err := fileStore.Put(ctx, key, file, fileSize, contentType)
+	if err != nil {
+		return Media{}, errmsg.Error // 返回上传错误
 	}
+
+	media := Media{
+		Key:      key,
+		Url:      url,
+		Size:     fileSize,
+		MimeType: contentType,
+		UserId:   user.ID,
+		Hash:     sum,
+		Variants: []MediaVariant{},
+	}
+	if code := CreateMedia(ctx, &media); code != errmsg.Success {
+		// 并发上传相同文件时，以先写入的记录为准
+		return reuseMedia(ctx, sum)
+	}
+
+	// 为图片生成缩略图，失败时不影响原图上传结果
+	if variantTypes[contentType] {
+		if _, err = file.Seek(0, io.SeekStart); err == nil {
+			err = createVariants(ctx, &media, file)
+		}
+		if err != nil {
+			logrus.WithContext(ctx).WithField("Key", key).Warnf("生成缩略图失败: %v", err)
+		}
+	}
+	return media, errmsg.Success
 }
 
-// selectZone 根据区域编号选择七牛云存储区域
-// 参数：id - 区域编号（1-华东，2-华北，3-华南）
-// 返回值：*storage.Zone - 对应的存储区域指针
-func selectZone(id int) *storage.Zone {
-	switch id {
-	case 1:
-		return &storage.ZoneHuadong // 华东区域
-	case 2:
-		return &storage.ZoneHuabei // 华北区域
-	case 3:
-		return &storage.ZoneHuanan // 华南区域
-	default:
-		return &storage.ZoneHuadong // 默认华东区域
+// createVariants 按配置的宽度生成 JPEG 缩略图，只生成比原图窄的尺寸
+func createVariants(ctx context.Context, media *Media, file io.Reader) error {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+	img, format, err := imageutil.Decode(data)
+	if err != nil {
+		return err
+	}
+	// 缩略图不带 EXIF，需要先按方向标记旋转（保留元数据或 WebP 保留方向标记时原图仍带有方向）
+	img = imageutil.ApplyOrientation(img, imageutil.Orientation(data, format))
+
+	widths := slices.Clone(utils.ImageWidths)
+	slices.Sort(widths)
+	for _, width := range widths {
+		if width <= 0 || width >= img.Bounds().Dx() {
+			continue
+		}
+		thumb := imageutil.Resize(img, width)
+		out, err := imageutil.EncodeJPEG(thumb, utils.ImageQuality)
+		if err != nil {
+			return err
+		}
+
+		key := fmt.Sprintf("%s_%d.jpg", media.Hash, width)
+		url, err := fileStore.Put(ctx, key, bytes.NewReader(out), int64(len(out)), "image/jpeg")
+		if err != nil {
+			return err
+		}
+		variant := MediaVariant{
+			MediaId:  media.ID,
+			Width:    width,
+			Height:   thumb.Bounds().Dy(),
+			Key:      key,
+			Url:      url,
+			Size:     int64(len(out)),
+			MimeType: "image/jpeg",
+		}
+		if err = db.WithContext(ctx).Create(&variant).Error; err != nil {
+			return err
+		}
+		media.Variants = append(media.Variants, variant)
 	}
+	return nil
 }
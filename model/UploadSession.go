@@ -0,0 +1,223 @@
+package model
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"ginblog/utils"
+	"ginblog/utils/errmsg"
+	"github.com/sirupsen/logrus"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// UploadSession 分片上传会话
+// 分片以 "<ChunkDir>/<会话ID>/<分片序号>" 的形式暂存在本地，合并后交给存储后端
+type UploadSession struct {
+	ID          string    `gorm:"type:char(32);primaryKey" json:"upload_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UserId      uint      `gorm:"type:int;not null;index" json:"user_id"`
+	FileName    string    `gorm:"type:varchar(200);not null" json:"file_name" validate:"required,max=200" label:"文件名"`
+	Size        int64     `gorm:"type:bigint;not null" json:"size" validate:"required,gt=0" label:"文件大小"`
+	ChunkSize   int64     `gorm:"type:bigint;not null" json:"chunk_size"`
+	TotalChunks int       `gorm:"type:int;not null" json:"total_chunks"`
+	ExpiresAt   time.Time `gorm:"not null;index" json:"expires_at"`
+}
+
+// CreateUploadSession 创建分片上传会话
+// 未指定分片大小或超过上限时使用配置的分片大小，小于下限时使用下限，避免分片数量过多
+func CreateUploadSession(ctx context.Context, data *UploadSession) int {
+	if data.Size > utils.ChunkMaxSize {
+		return errmsg.ErrorUploadTooLarge
+	}
+	if data.ChunkSize <= 0 || data.ChunkSize > utils.ChunkSize {
+		data.ChunkSize = utils.ChunkSize
+	}
+	if data.ChunkSize < utils.ChunkMinSize {
+		data.ChunkSize = utils.ChunkMinSize
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return errmsg.Error
+	}
+	data.ID = hex.EncodeToString(id)
+	data.TotalChunks = int((data.Size + data.ChunkSize - 1) / data.ChunkSize)
+	data.ExpiresAt = time.Now().Add(utils.ChunkExpire)
+
+	if err := os.MkdirAll(data.chunkDir(), 0755); err != nil {
+		return errmsg.Error
+	}
+	if err := db.WithContext(ctx).Create(&data).Error; err != nil {
+		_ = os.RemoveAll(data.chunkDir())
+		return errmsg.Error
+	}
+	return errmsg.Success
+}
+
+// GetUploadSession 查询当前用户未过期的上传会话
+func GetUploadSession(ctx context.Context, id string, userId uint) (UploadSession, int) {
+	var session UploadSession
+	db.WithContext(ctx).Where("id = ? AND user_id = ? AND expires_at > ?", id, userId, time.Now()).First(&session)
+	if session.ID == "" {
+		return session, errmsg.ErrorUploadSessionNotExist
+	}
+	return session, errmsg.Success
+}
+
+// PutChunk 保存一个分片（重复上传同一分片会覆盖），并延长会话有效期
+func PutChunk(ctx context.Context, session *UploadSession, index int, r io.Reader) int {
+	if index < 0 || index >= session.TotalChunks {
+		return errmsg.ErrorUploadChunkInvalid
+	}
+	// 除最后一个分片外，每个分片的大小必须等于分片大小
+	expected := session.ChunkSize
+	if index == session.TotalChunks-1 {
+		expected = session.Size - int64(session.TotalChunks-1)*session.ChunkSize
+	}
+
+	tmp, err := os.CreateTemp(session.chunkDir(), ".chunk-*")
+	if err != nil {
+		return errmsg.Error
+	}
+	defer os.Remove(tmp.Name())
+
+	n, err := io.Copy(tmp, io.LimitReader(r, expected+1))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errmsg.Error
+	}
+	if n != expected {
+		return errmsg.ErrorUploadChunkInvalid
+	}
+	if err = os.Rename(tmp.Name(), session.chunkPath(index)); err != nil {
+		return errmsg.Error
+	}
+
+	session.ExpiresAt = time.Now().Add(utils.ChunkExpire)
+	db.WithContext(ctx).Model(session).Update("expires_at", session.ExpiresAt)
+	return errmsg.Success
+}
+
+// ReceivedChunks 查询已接收的分片序号（升序）
+func ReceivedChunks(session *UploadSession) ([]int, int) {
+	entries, err := os.ReadDir(session.chunkDir())
+	if err != nil {
+		return nil, errmsg.Error
+	}
+	chunks := make([]int, 0, len(entries))
+	for _, entry := range entries {
+		index, err := strconv.Atoi(entry.Name())
+		if err != nil || index < 0 || index >= session.TotalChunks {
+			continue
+		}
+		chunks = append(chunks, index)
+	}
+	slices.Sort(chunks)
+	return chunks, errmsg.Success
+}
+
+// CompleteUploadSession 合并全部分片并交给存储后端，成功后删除会话
+func CompleteUploadSession(ctx context.Context, session *UploadSession, user User) (Media, int) {
+	chunks, code := ReceivedChunks(session)
+	if code != errmsg.Success {
+		return Media{}, code
+	}
+	if len(chunks) != session.TotalChunks {
+		return Media{}, errmsg.ErrorUploadChunkMissing
+	}
+
+	// 按顺序合并分片到临时文件
+	file, err := os.CreateTemp(session.chunkDir(), ".merged-*")
+	if err != nil {
+		return Media{}, errmsg.Error
+	}
+	defer func() {
+		file.Close()
+		os.Remove(file.Name())
+	}()
+	for _, index := range chunks {
+		if err = appendChunk(file, session.chunkPath(index)); err != nil {
+			return Media{}, errmsg.Error
+		}
+	}
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return Media{}, errmsg.Error
+	}
+
+	contentType, code := CheckUploadFile(file, session.FileName, session.Size, utils.ChunkMaxSize)
+	if code != errmsg.Success {
+		return Media{}, code
+	}
+	media, code := UpLoadFile(ctx, file, session.Size, contentType, user)
+	if code != errmsg.Success {
+		return media, code
+	}
+
+	if err = removeUploadSession(ctx, session); err != nil {
+		logrus.WithContext(ctx).WithField("UploadId", session.ID).Warnf("删除上传会话失败: %v", err)
+	}
+	return media, errmsg.Success
+}
+
+// CleanExpiredUploadSessions 清理过期的上传会话及其暂存分片，返回清理数量
+func CleanExpiredUploadSessions(ctx context.Context) (int, error) {
+	var sessions []UploadSession
+	err := db.WithContext(ctx).Where("expires_at <= ?", time.Now()).Find(&sessions).Error
+	if err != nil {
+		return 0, err
+	}
+	for i := range sessions {
+		if err = removeUploadSession(ctx, &sessions[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(sessions), nil
+}
+
+// StartUploadSessionGC 启动过期上传会话的定时清理任务
+func StartUploadSessionGC() {
+	runPeriodically("upload-session-gc", time.Hour, func(ctx context.Context) error {
+		removed, err := CleanExpiredUploadSessions(ctx)
+		if removed > 0 {
+			logrus.WithField("Task", "upload-session-gc").Infof("已清理过期上传会话 %d 个", removed)
+		}
+		return err
+	})
+}
+
+// removeUploadSession 删除会话的暂存分片和会话记录
+func removeUploadSession(ctx context.Context, session *UploadSession) error {
+	if err := os.RemoveAll(session.chunkDir()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return db.WithContext(ctx).Delete(session).Error
+}
+
+// appendChunk 将分片内容追加到合并文件
+func appendChunk(dst io.Writer, path string) error {
+	chunk, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer chunk.Close()
+	_, err = io.Copy(dst, chunk)
+	return err
+}
+
+// chunkDir 会话的分片暂存目录
+func (s *UploadSession) chunkDir() string {
+	return filepath.Join(utils.ChunkDir, s.ID)
+}
+
+// chunkPath 分片的暂存路径
+func (s *UploadSession) chunkPath(index int) string {
+	return filepath.Join(s.chunkDir(), strconv.Itoa(index))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"ginblog/utils"
 	"ginblog/utils/errmsg"
 	"golang.org/x/crypto/scrypt"
 	"gorm.io/gorm"
@@ -15,6 +16,7 @@ type User struct {
 	Username   string `gorm:"type:varchar(20);not null " json:"username" validate:"required,min=4,max=12" label:"用户名"` // 用户名，数据库约束：长度20，非空
 	Password   string `gorm:"type:varchar(500);not null" json:"password" validate:"required,min=6,max=120" label:"密码"` // 密码，存储加密后的值（包含盐值），非空
 	Role       int    `gorm:"type:int;DEFAULT:2" json:"role" validate:"required,gte=2" label:"角色码"`                    // 角色，1-管理员，2-普通用户，默认值2
+	TokenVer   int    `gorm:"type:int;not null;default:0" json:"-"`                                                    // 令牌版本，修改密码后递增使旧令牌失效
 }
 
 // CheckUser 检查用户名是否存在
@@ -30,6 +32,29 @@ func CheckUser(ctx context.Context, name string) (code int) {
 	return errmsg.Success // 返回成功码 200
 }
 
+// GetUserByName 根据用户名查询用户（用于解析JWT中的当前用户）
+func GetUserByName(ctx context.Context, username string) (User, int) {
+	var user User
+	db.WithContext(ctx).Select("id, username, role").Where("username = ?", username).First(&user)
+	if user.ID == 0 {
+		return user, errmsg.ErrorUserNotExist
+	}
+	return user, errmsg.Success
+}
+
+// IsAdmin 是否为管理员
+func (u *User) IsAdmin() bool {
+	return u.Role == 1
+}
+
+// sanitizePolicy 用户角色对应的 HTML 清理策略：管理员使用 AdminPolicy，其他角色使用 UserPolicy
+func sanitizePolicy(role int) string {
+	if role == 1 {
+		return utils.SanitizeAdminPolicy
+	}
+	return utils.SanitizeUserPolicy
+}
+
 // CheckUpUser 更新查询
 func CheckUpUser(ctx context.Context, id int, name string) (code int) {
 	var user User
@@ -94,6 +119,59 @@ func EditUser(ctx context.Context, id int, data *User) int {
 	return errmsg.Success
 }
 
+// ChangePassword 用户修改密码（需验证旧密码）
+// 修改成功后令牌版本递增，该用户已签发的所有令牌失效
+func ChangePassword(ctx context.Context, id int, oldPassword string, newPassword string) int {
+	var user User
+	db.WithContext(ctx).Select("id, password").Where("id = ?", id).First(&user)
+	if user.ID == 0 {
+		return errmsg.ErrorUserNotExist
+	}
+	if code := VerifyScryptPassword(user.Password, oldPassword); code != errmsg.Success {
+		return code
+	}
+	return updatePassword(ctx, id, newPassword)
+}
+
+// ResetPassword 管理员重置用户密码
+func ResetPassword(ctx context.Context, id int, newPassword string) int {
+	var user User
+	db.WithContext(ctx).Select("id").Where("id = ?", id).First(&user)
+	if user.ID == 0 {
+		return errmsg.ErrorUserNotExist
+	}
+	return updatePassword(ctx, id, newPassword)
+}
+
+// updatePassword 加密保存新密码并递增令牌版本
+func updatePassword(ctx context.Context, id int, password string) int {
+	hash := ScryptPw(password)
+	if hash == "" {
+		return errmsg.Error
+	}
+	var maps = make(map[string]interface{})
+	maps["password"] = hash
+	maps["token_ver"] = gorm.Expr("token_ver + ?", 1)
+	err := db.WithContext(ctx).Model(&User{}).Where("id = ? ", id).Updates(maps).Error
+	if err != nil {
+		return errmsg.Error
+	}
+	return errmsg.Success
+}
+
+// CheckTokenVer 校验令牌版本，用户不存在或密码已修改时令牌失效
+func CheckTokenVer(ctx context.Context, username string, tokenVer int) int {
+	var user User
+	db.WithContext(ctx).Select("id, token_ver").Where("username = ?", username).First(&user)
+	if user.ID == 0 {
+		return errmsg.ErrorUserNotExist
+	}
+	if user.TokenVer != tokenVer {
+		return errmsg.ErrorTokenRevoked
+	}
+	return errmsg.Success
+}
+
 // DeleteUser 删除用户
 func DeleteUser(ctx context.Context, id int) int {
 	var user User
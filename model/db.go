@@ -45,6 +45,7 @@ func InitDb() {
 			SingularTable: true, // 单数表名
 		},
 		SkipDefaultTransaction:                   true,  // 禁用默认事务
+		TranslateError:                           true,  // 将唯一索引冲突等数据库错误转换为 gorm 错误（如 gorm.ErrDuplicatedKey）
 		DisableForeignKeyConstraintWhenMigrating: false, // 注意这里保持外键约束
 	}
 
@@ -96,7 +97,7 @@ func InitDb() {
 	sqlDB.SetConnMaxIdleTime(30 * time.Minute)
 
 	// 自动迁移
-	if err := db.AutoMigrate(&User{}, &Article{}, &Category{}); err != nil {
+	if err := db.AutoMigrate(&User{}, &Article{}, &Category{}, &Comment{}, &Profile{}, &Tag{}, &Media{}, &MediaVariant{}, &UploadSession{}, &SlugHistory{}, &ArticleRevision{}, &ArticleAutosave{}, &ArticleLock{}); err != nil {
 		log.Fatal("数据库迁移失败: ", err)
 		os.Exit(1)
 	}
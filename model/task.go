@@ -0,0 +1,37 @@
+package model
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+// runPeriodically 在后台按固定间隔执行任务（启动时先执行一次）
+// 任务返回的错误只记录日志，不会中断后续执行
+func runPeriodically(name string, interval time.Duration, task func(ctx context.Context) error) {
+	if interval <= 0 {
+		logrus.WithField("Task", name).Info("后台任务已禁用")
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			ctx := context.WithValue(context.Background(), "RequestID", "task-"+name)
+			if err := task(ctx); err != nil {
+				logrus.WithField("Task", name).Errorf("后台任务执行失败: %v", err)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// runOnce 在后台执行一次性任务（如历史数据回填），错误只记录日志
+func runOnce(name string, task func(ctx context.Context) error) {
+	go func() {
+		ctx := context.WithValue(context.Background(), "RequestID", "task-"+name)
+		if err := task(ctx); err != nil {
+			logrus.WithField("Task", name).Errorf("后台任务执行失败: %v", err)
+		}
+	}()
+}
@@ -4,6 +4,7 @@ import (
 	v1 "ginblog/api/v1"
 	"ginblog/middleware"
 	"ginblog/utils"
+	"ginblog/utils/storage"
 	"github.com/gin-gonic/gin"
 )
 
@@ -25,10 +26,17 @@ func InitRouter() {
 	r.Use(middleware.Logger())
 	r.Use(gin.Recovery())
 	r.Use(middleware.Cors())
+
+	// 使用本地存储时，由 gin 提供上传文件的静态访问
+	if utils.StorageType == storage.TypeLocal {
+		r.Static(utils.LocalRoute, utils.LocalPath)
+	}
+
 	// 创建API路由分组（版本控制）
 	// 所有路由将以 /api/v1/ 作为前缀
+	// 后台管理接口，需要管理员令牌
 	auth := r.Group("api/v1")
-	auth.Use(middleware.JwtToken())
+	auth.Use(middleware.JwtToken(), middleware.AdminOnly())
 	{
 		// 用户模块的路由接口
 		//新增用户
@@ -38,8 +46,8 @@ func InitRouter() {
 		//删除用户
 		auth.DELETE("user/:id", v1.DeleteUser)
 
-		//修改密码
-		//auth.PUT("admin/changepw/:id", v1.ChangeUserPassword)
+		//重置密码（管理员）
+		auth.PUT("admin/changepw/:id", v1.ResetUserPassword)
 
 		// 分类模块的路由接口
 		//添加分类
@@ -56,16 +64,95 @@ func InitRouter() {
 		auth.PUT("article/:id", v1.EditArt)
 		//删除文章
 		auth.DELETE("article/:id", v1.DeleteArt)
+		//发布文章
+		auth.PUT("article/publish/:id", v1.PublishArt)
+		//撤回文章为草稿
+		auth.PUT("article/unpublish/:id", v1.UnpublishArt)
+		//归档文章
+		auth.PUT("article/archive/:id", v1.ArchiveArt)
+		//设置定时发布时间和过期时间
+		auth.PUT("article/schedule/:id", v1.ScheduleArt)
+		//查询文章的版本列表
+		auth.GET("article/revisions/:id", v1.GetRevisions)
+		//比较文章的两个版本（from、to 为版本号）
+		auth.GET("article/revisions/:id/diff", v1.DiffRevisions)
+		//查询文章的指定版本
+		auth.GET("article/revisions/:id/:number", v1.GetRevision)
+		//将文章恢复为指定版本
+		auth.POST("article/revisions/:id/:number/restore", v1.RestoreRevision)
+		//自动保存文章的工作副本
+		auth.PUT("article/autosave/:id", v1.SaveAutosave)
+		//查询最新的自动保存内容
+		auth.GET("article/autosave/:id", v1.GetAutosave)
+		//丢弃自动保存的内容
+		auth.DELETE("article/autosave/:id", v1.DeleteAutosave)
+		//将自动保存的内容保存到文章
+		auth.POST("article/autosave/:id/publish", v1.PublishAutosave)
+		//获取文章编辑锁
+		auth.POST("article/lock/:id", v1.AcquireLock)
+		//续期文章编辑锁
+		auth.PUT("article/lock/:id", v1.HeartbeatLock)
+		//释放文章编辑锁
+		auth.DELETE("article/lock/:id", v1.ReleaseLock)
+		//强制获取文章编辑锁（管理员）
+		auth.POST("admin/article/lock/:id", v1.ForceLock)
+		//查询文章列表（全部状态，status 参数筛选）
+		auth.GET("admin/article", v1.GetArt)
+		//查询单个文章信息（全部状态）
+		auth.GET("admin/article/info/:id", v1.GetArtInfo)
+		//查询分类下的所有文章（全部状态）
+		auth.GET("admin/article/list/:id", v1.GetCateArt)
+		//查询标签下的所有文章（全部状态）
+		auth.GET("admin/article/tag/:id", v1.GetTagArt)
 		// 上传文件
 		auth.POST("upload", v1.UpLoad)
-		//// 更新个人设置
-		//auth.GET("admin/profile/:id", v1.GetProfile)
-		//auth.PUT("profile/:id", v1.UpdateProfile)
-		//// 评论模块
-		//auth.GET("comment/list", v1.GetCommentList)
-		//auth.DELETE("delcomment/:id", v1.DeleteComment)
-		//auth.PUT("checkcomment/:id", v1.CheckComment)
-		//auth.PUT("uncheckcomment/:id", v1.UncheckComment)
+		// 分片上传
+		//创建上传会话
+		auth.POST("upload/chunk/init", v1.InitChunkUpload)
+		//上传分片
+		auth.PUT("upload/chunk/:id/:index", v1.PutChunk)
+		//查询已接收的分片
+		auth.GET("upload/chunk/:id", v1.GetChunkUpload)
+		//合并分片完成上传
+		auth.POST("upload/chunk/:id/complete", v1.CompleteChunkUpload)
+		// 浏览器直传
+		//申请直传凭证
+		auth.POST("upload/presign", v1.PresignUpload)
+		//直传完成回调
+		auth.POST("upload/presign/callback", v1.PresignCallback)
+		// 媒体库
+		//查询媒体列表（支持关键字搜索）
+		auth.GET("media", v1.GetMediaList)
+		//删除媒体
+		auth.DELETE("media/:id", v1.DeleteMedia)
+
+		// 个人设置
+		//查询个人资料
+		auth.GET("admin/profile/:id", v1.GetProfile)
+
+		// 评论模块
+		//查询评论列表（全部状态）
+		auth.GET("comment/list", v1.GetCommentList)
+		//查询单个评论信息（全部状态）
+		auth.GET("admin/comment/info/:id", v1.GetComment)
+		//删除评论
+		auth.DELETE("delcomment/:id", v1.DeleteComment)
+		//审核通过评论
+		auth.PUT("checkcomment/:id", v1.CheckComment)
+		//撤下评论
+		auth.PUT("uncheckcomment/:id", v1.UncheckComment)
+	}
+
+	// 前台接口，需要登录（前台登录签发的普通用户令牌即可）
+	reader := r.Group("api/v1")
+	reader.Use(middleware.JwtToken())
+	{
+		//修改密码（本人，需验证旧密码）
+		reader.PUT("changepw/:id", v1.ChangeUserPassword)
+		//更新个人资料（仅本人或管理员）
+		reader.PUT("profile/:id", v1.UpdateProfile)
+		//提交评论（需审核后展示，评论者为当前登录用户）
+		reader.POST("addcomment", v1.AddComment)
 	}
 
 	router := r.Group("api/v1")
@@ -80,13 +167,35 @@ func InitRouter() {
 		//查询具体分类
 		router.GET("category/:id", v1.GetCateInfo)
 
+		// 标签模块的路由接口
+		//查询标签列表（含文章数）
+		router.GET("tag", v1.GetTags)
+
 		//文章模块的路由接口
 		//查询文章列表
 		router.GET("article", v1.GetArt)
 		//查询单个文章信息
 		router.GET("article/info/:id", v1.GetArtInfo)
+		//按别名查询单个文章信息（历史别名重定向到当前别名）
+		router.GET("article/slug/:slug", v1.GetArtBySlug)
 		//查询分类下的所有文章
 		router.GET("article/list/:id", v1.GetCateArt)
+		//查询标签下的所有文章
+		router.GET("article/tag/:id", v1.GetTagArt)
+		//查询文章的评论树（按顶层评论分页）
+		router.GET("article/comment/:id", v1.GetCommentTree)
+		//获取代码高亮样式表（theme 参数指定配色主题）
+		router.GET("highlight.css", v1.GetHighlightCSS)
+		//查询可选的代码高亮配色主题
+		router.GET("highlight/themes", v1.GetHighlightThemes)
+
+		// 评论模块的路由接口
+		//查询单个评论信息（已审核且文章可见）
+		router.GET("comment/info/:id", v1.GetComment)
+		//查询文章下已审核的评论
+		router.GET("commentfront/:id", v1.GetCommentListFront)
+		//查询文章下已审核的评论数
+		router.GET("commentcount/:id", v1.GetCommentCount)
 
 		// 登录控制模块
 		router.POST("login", v1.Login)
@@ -7,7 +7,7 @@ const (
 	Error   = 500 // 通用错误状态码
 )
 
-// 用户模块错误码 (1001-1008)
+// 用户模块错误码 (1001-1010)
 const (
 	ErrorUsernameUsed   = 1001 + iota // 用户名已被使用
 	ErrorPasswordWrong                // 密码不正确
@@ -18,17 +18,53 @@ const (
 	ErrorTokenWrong                   // TOKEN无效
 	ErrorTokenTypeWrong               // TOKEN类型错误
 	ErrorUserNoRight                  // 用户无权限
+	ErrorTokenRevoked                 // TOKEN已失效（密码已修改）
 )
 
-// ErrorArtNotExist 文章模块错误码 (2001)
+// 文章模块错误码 (2001-2009)
 const (
-	ErrorArtNotExist = 2001 // 文章不存在
+	ErrorArtNotExist         = 2001 + iota // 文章不存在
+	ErrorArtSlugUsed                       // 文章别名已被使用
+	ErrorArtSlugInvalid                    // 文章别名格式错误
+	ErrorArtScheduleInvalid                // 定时发布时间错误
+	ErrorArtRevisionNotExist               // 文章版本不存在
+	ErrorArtAutosaveNotExist               // 没有自动保存的内容
+	ErrorArtVersionConflict                // 文章已被他人修改
+	ErrorArtLocked                         // 文章正在被他人编辑
+	ErrorArtLockNotHeld                    // 未持有文章编辑锁
 )
 
-// 分类模块错误码 (3001-3002)
+// 分类模块错误码 (3001-3003)
 const (
-	ErrorCatenameUsed = 3001 + iota // 分类名称已存在
-	ErrorCateNotExist               // 分类不存在
+	ErrorCatenameUsed        = 3001 + iota // 分类名称已存在
+	ErrorCateNotExist                      // 分类不存在
+	ErrorCateVersionConflict               // 分类已被他人修改
+)
+
+// 评论模块错误码 (4001)
+const (
+	ErrorCommentNotExist = 4001 + iota // 评论不存在
+)
+
+// ErrorTagNotExist 标签模块错误码 (5001-5002)
+const (
+	ErrorTagNotExist    = 5001 // 标签不存在
+	ErrorTagNameTooLong = 5002 // 标签名过长
+)
+
+// 上传模块错误码 (6001-6011)
+const (
+	ErrorUploadNoFile             = 6001 + iota // 未选择文件
+	ErrorUploadTooLarge                         // 文件过大
+	ErrorUploadTypeNotAllowed                   // 文件类型不允许
+	ErrorUploadExtNotAllowed                    // 扩展名与文件类型不符
+	ErrorMediaNotExist                          // 媒体不存在
+	ErrorUploadBadImage                         // 图片文件损坏
+	ErrorUploadSessionNotExist                  // 上传会话不存在或已过期
+	ErrorUploadChunkInvalid                     // 分片序号或大小错误
+	ErrorUploadChunkMissing                     // 分片未上传完整
+	ErrorUploadPresignUnsupported               // 存储后端不支持直传
+	ErrorUploadCallbackInvalid                  // 直传凭证无效或文件不匹配
 )
 
 // codeMsg 错误码与错误信息的映射表
@@ -45,13 +81,43 @@ var codeMsg = map[int]string{
 	ErrorTokenWrong:     "无效的身份令牌",
 	ErrorTokenTypeWrong: "非法的令牌格式",
 	ErrorUserNoRight:    "用户权限不足",
+	ErrorTokenRevoked:   "密码已修改，请重新登录",
 
 	// 文章模块
-	ErrorArtNotExist: "指定文章不存在",
+	ErrorArtNotExist:         "指定文章不存在",
+	ErrorArtSlugUsed:         "文章别名已被使用",
+	ErrorArtSlugInvalid:      "文章别名只能包含小写字母、数字和连字符",
+	ErrorArtScheduleInvalid:  "发布时间必须晚于当前时间，过期时间必须晚于发布时间",
+	ErrorArtRevisionNotExist: "指定文章版本不存在",
+	ErrorArtAutosaveNotExist: "没有自动保存的内容",
+	ErrorArtVersionConflict:  "文章已被他人修改，请基于最新内容重新编辑",
+	ErrorArtLocked:           "文章正在被其他用户编辑",
+	ErrorArtLockNotHeld:      "未持有文章编辑锁或编辑锁已被他人获取",
 
 	// 分类模块
-	ErrorCatenameUsed: "分类名称已存在",
-	ErrorCateNotExist: "指定分类不存在",
+	ErrorCatenameUsed:        "分类名称已存在",
+	ErrorCateNotExist:        "指定分类不存在",
+	ErrorCateVersionConflict: "分类已被他人修改，请基于最新内容重新编辑",
+
+	// 评论模块
+	ErrorCommentNotExist: "评论不存在",
+
+	// 标签模块
+	ErrorTagNotExist:    "指定标签不存在",
+	ErrorTagNameTooLong: "标签名不能超过 30 个字符",
+
+	// 上传模块
+	ErrorUploadNoFile:             "请选择要上传的文件",
+	ErrorUploadTooLarge:           "文件大小超出限制",
+	ErrorUploadTypeNotAllowed:     "不支持的文件类型",
+	ErrorUploadExtNotAllowed:      "文件扩展名与文件内容不符",
+	ErrorMediaNotExist:            "指定媒体不存在",
+	ErrorUploadBadImage:           "图片文件已损坏或无法解析",
+	ErrorUploadSessionNotExist:    "上传会话不存在或已过期",
+	ErrorUploadChunkInvalid:       "分片序号或大小错误",
+	ErrorUploadChunkMissing:       "分片尚未全部上传",
+	ErrorUploadPresignUnsupported: "当前存储不支持直传，请通过服务器上传",
+	ErrorUploadCallbackInvalid:    "直传凭证无效、已过期或文件不匹配",
 }
 
 // GetErrMsg 根据错误码获取对应的错误信息
@@ -0,0 +1,60 @@
+// Package imageutil 上传图片处理（缩放、重新编码、元数据清理）
+package imageutil
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+
+	_ "image/gif" // 注册 GIF 解码器
+	_ "image/png" // 注册 PNG 解码器
+
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // 注册 WebP 解码器
+)
+
+// MaxPixels 允许解码的最大像素数，防止超大尺寸图片耗尽内存
+const MaxPixels = 40_000_000
+
+// ErrTooLarge 图片像素尺寸超出限制
+var ErrTooLarge = errors.New("图片尺寸超出限制")
+
+// Decode 解码图片，返回图片和格式名（jpeg/png/gif/webp）
+func Decode(data []byte) (image.Image, string, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	if cfg.Width*cfg.Height > MaxPixels {
+		return nil, "", ErrTooLarge
+	}
+	return image.Decode(bytes.NewReader(data))
+}
+
+// Resize 按宽度等比缩放图片（使用 Catmull-Rom 插值）
+func Resize(img image.Image, width int) image.Image {
+	bounds := img.Bounds()
+	height := bounds.Dy() * width / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, xdraw.Over, nil)
+	return dst
+}
+
+// EncodeJPEG 将图片编码为 JPEG，透明区域以白色填充
+func EncodeJPEG(img image.Image, quality int) ([]byte, error) {
+	bounds := img.Bounds()
+	canvas := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	xdraw.Draw(canvas, canvas.Bounds(), image.NewUniform(color.White), image.Point{}, xdraw.Src)
+	xdraw.Draw(canvas, canvas.Bounds(), img, bounds.Min, xdraw.Over)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, canvas, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,331 @@
+package imageutil
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+)
+
+// ErrFormat 图片数据格式无法识别
+var ErrFormat = errors.New("无法识别的图片格式")
+
+// StripMetadata 清除图片中的 EXIF/XMP/IPTC 及文本元数据（GPS坐标、相机序列号等）
+// format 为 Decode 返回的格式名，支持 jpeg/png/webp，其他格式原样返回
+// JPEG 和 PNG 带有旋转方向时会先按方向旋转像素再重新编码，保证清除方向信息后仍能正确显示；
+// WebP 没有可用的编码器，改为保留只含方向标记的最小 EXIF
+func StripMetadata(data []byte, format string, quality int) ([]byte, error) {
+	switch format {
+	case "jpeg":
+		if o := Orientation(data, format); o > 1 {
+			img, _, err := Decode(data)
+			if err != nil {
+				return nil, err
+			}
+			var buf bytes.Buffer
+			if err = jpeg.Encode(&buf, ApplyOrientation(img, o), &jpeg.Options{Quality: quality}); err != nil {
+				return nil, err
+			}
+			return buf.Bytes(), nil
+		}
+		return stripJPEG(data)
+	case "png":
+		if o := Orientation(data, format); o > 1 {
+			img, _, err := Decode(data)
+			if err != nil {
+				return nil, err
+			}
+			var buf bytes.Buffer
+			if err = png.Encode(&buf, ApplyOrientation(img, o)); err != nil {
+				return nil, err
+			}
+			return buf.Bytes(), nil
+		}
+		return stripPNG(data)
+	case "webp":
+		return stripWebP(data, Orientation(data, format))
+	default:
+		return data, nil
+	}
+}
+
+// Orientation 读取图片 EXIF 中的方向标记（1-8），没有或无法识别时返回 0
+// 支持 JPEG 的 APP1 段、PNG 的 eXIf 块和 WebP 的 EXIF 块
+func Orientation(data []byte, format string) int {
+	switch format {
+	case "jpeg":
+		return jpegOrientation(data)
+	case "png":
+		return pngOrientation(data)
+	case "webp":
+		return webpOrientation(data)
+	default:
+		return 0
+	}
+}
+
+// stripJPEG 去除 JPEG 中的 APP1(EXIF/XMP)、APP13(IPTC) 和注释段
+// 保留 APP0(JFIF)、APP2(ICC 色彩配置)、APP14(Adobe) 等影响显示的段
+func stripJPEG(data []byte) ([]byte, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, ErrFormat
+	}
+	out := make([]byte, 0, len(data))
+	out = append(out, 0xFF, 0xD8)
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return nil, ErrFormat
+		}
+		marker := data[pos+1]
+		// 填充字节
+		if marker == 0xFF {
+			pos++
+			continue
+		}
+		// 图像数据开始（SOS）后原样保留
+		if marker == 0xDA {
+			return append(out, data[pos:]...), nil
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, ErrFormat
+		}
+		switch marker {
+		case 0xE1, 0xED, 0xFE: // APP1、APP13、COM
+		default:
+			out = append(out, data[pos:end]...)
+		}
+		pos = end
+	}
+	return nil, ErrFormat
+}
+
+// stripPNG 去除 PNG 中的 eXIf、文本和时间辅助块，缺少 IEND 块（文件被截断）时返回错误
+func stripPNG(data []byte) ([]byte, error) {
+	const sigLen = 8
+	if len(data) < sigLen || string(data[:sigLen]) != "\x89PNG\r\n\x1a\n" {
+		return nil, ErrFormat
+	}
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:sigLen]...)
+	pos := sigLen
+	for pos+12 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		end := pos + 12 + length // 长度(4) + 类型(4) + 数据 + CRC(4)
+		if end > len(data) {
+			return nil, ErrFormat
+		}
+		switch string(data[pos+4 : pos+8]) {
+		case "eXIf", "tEXt", "zTXt", "iTXt", "tIME":
+		case "IEND":
+			// 图像结束，丢弃之后附加的数据
+			return append(out, data[pos:end]...), nil
+		default:
+			out = append(out, data[pos:end]...)
+		}
+		pos = end
+	}
+	return nil, ErrFormat
+}
+
+// stripWebP 去除 WebP 中的 EXIF 和 XMP 块，并清除 VP8X 中对应的标记位
+// orientation 大于 1 时写入只含方向标记的 EXIF 块（需要 VP8X 块才能携带 EXIF）
+func stripWebP(data []byte, orientation int) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, ErrFormat
+	}
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:12]...)
+	pos := 12
+	extended := false
+	for pos+8 <= len(data) {
+		fourCC := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		end := pos + 8 + size
+		if end > len(data) {
+			return nil, ErrFormat
+		}
+		if size%2 == 1 && end < len(data) {
+			end++ // 块数据按偶数字节对齐（末尾块缺少填充字节时容忍）
+		}
+		switch fourCC {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			if size < 10 {
+				return nil, ErrFormat
+			}
+			start := len(out)
+			out = append(out, data[pos:end]...)
+			out[start+8] &^= 0x08 | 0x04 // EXIF 标记、XMP 标记
+			if orientation > 1 {
+				out[start+8] |= 0x08
+			}
+			extended = true
+		default:
+			out = append(out, data[pos:end]...)
+		}
+		pos = end
+	}
+	if orientation > 1 && extended {
+		exif := orientationExif(orientation)
+		out = binary.LittleEndian.AppendUint32(append(out, "EXIF"...), uint32(len(exif)))
+		out = append(out, exif...)
+	}
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out, nil
+}
+
+// orientationExif 生成只含方向标记的 EXIF 数据（小端 TIFF，IFD0 只有一项）
+func orientationExif(orientation int) []byte {
+	tiff := []byte("II*\x00\x08\x00\x00\x00")        // 字节序、标识、IFD0 偏移
+	tiff = binary.LittleEndian.AppendUint16(tiff, 1) // 项数
+	tiff = binary.LittleEndian.AppendUint16(tiff, 0x0112)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 3) // SHORT
+	tiff = binary.LittleEndian.AppendUint32(tiff, 1)
+	tiff = binary.LittleEndian.AppendUint16(tiff, uint16(orientation))
+	tiff = append(tiff, 0, 0)                        // 值按 4 字节对齐
+	return binary.LittleEndian.AppendUint32(tiff, 0) // 没有下一个 IFD
+}
+
+// jpegOrientation 读取 JPEG EXIF 中的方向标记（1-8），没有时返回 0
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 0
+	}
+	pos := 2
+	for pos+4 <= len(data) && data[pos] == 0xFF {
+		marker := data[pos+1]
+		if marker == 0xDA {
+			return 0
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return 0
+		}
+		if marker == 0xE1 && bytes.HasPrefix(data[pos+4:end], []byte("Exif\x00\x00")) {
+			return exifOrientation(data[pos+10 : end])
+		}
+		pos = end
+	}
+	return 0
+}
+
+// pngOrientation 读取 PNG eXIf 块中的方向标记，没有时返回 0
+func pngOrientation(data []byte) int {
+	const sigLen = 8
+	if len(data) < sigLen || string(data[:sigLen]) != "\x89PNG\r\n\x1a\n" {
+		return 0
+	}
+	pos := sigLen
+	for pos+12 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		end := pos + 12 + length
+		if end > len(data) {
+			return 0
+		}
+		if string(data[pos+4:pos+8]) == "eXIf" {
+			return exifOrientation(data[pos+8 : end-4])
+		}
+		pos = end
+	}
+	return 0
+}
+
+// webpOrientation 读取 WebP EXIF 块中的方向标记，没有时返回 0
+func webpOrientation(data []byte) int {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return 0
+	}
+	pos := 12
+	for pos+8 <= len(data) {
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		end := pos + 8 + size
+		if end > len(data) {
+			return 0
+		}
+		if string(data[pos:pos+4]) == "EXIF" {
+			// 部分编码器在 TIFF 数据前保留了 JPEG 的 "Exif\0\0" 前缀
+			return exifOrientation(bytes.TrimPrefix(data[pos+8:end], []byte("Exif\x00\x00")))
+		}
+		pos = end + size%2
+	}
+	return 0
+}
+
+// exifOrientation 从 TIFF 格式的 EXIF 数据中读取 IFD0 的方向标记（0x0112）
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 0
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			o := int(order.Uint16(tiff[entry+8:]))
+			if o < 1 || o > 8 {
+				return 0
+			}
+			return o
+		}
+	}
+	return 0
+}
+
+// ApplyOrientation 按 EXIF 方向标记（1-8）旋转或翻转图片
+func ApplyOrientation(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	// 5-8 需要交换宽高
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // 水平翻转
+				dx, dy = w-1-x, y
+			case 3: // 旋转 180°
+				dx, dy = w-1-x, h-1-y
+			case 4: // 垂直翻转
+				dx, dy = x, h-1-y
+			case 5: // 沿左上-右下对角线翻转
+				dx, dy = y, x
+			case 6: // 顺时针旋转 90°
+				dx, dy = h-1-y, x
+			case 7: // 沿右上-左下对角线翻转
+				dx, dy = h-1-y, w-1-x
+			case 8: // 逆时针旋转 90°
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,271 @@
+package imageutil
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// testImage 生成 w×h 的测试图片，左上角像素为红色，其余为白色
+func testImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.White)
+		}
+	}
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+	return img
+}
+
+// exifWithGPS 生成带方向标记和额外标签（模拟 GPS 等敏感信息）的 EXIF 数据
+func exifWithGPS(orientation int) []byte {
+	tiff := []byte("MM\x00*\x00\x00\x00\x08")
+	tiff = binary.BigEndian.AppendUint16(tiff, 2)
+	tiff = binary.BigEndian.AppendUint16(tiff, 0x0112)
+	tiff = binary.BigEndian.AppendUint16(tiff, 3)
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, uint16(orientation))
+	tiff = append(tiff, 0, 0)
+	tiff = binary.BigEndian.AppendUint16(tiff, 0x8825) // GPS IFD
+	tiff = binary.BigEndian.AppendUint16(tiff, 4)
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint32(tiff, 0x47505321)
+	return binary.BigEndian.AppendUint32(tiff, 0)
+}
+
+// jpegWithExif 生成在 SOI 后插入 EXIF 和注释段的 JPEG
+func jpegWithExif(t *testing.T, img image.Image, orientation int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 90}); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	app1 := append([]byte("Exif\x00\x00"), exifWithGPS(orientation)...)
+	com := []byte("secret comment")
+	out := append([]byte{}, data[:2]...)
+	out = append(out, 0xFF, 0xE1)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(app1)+2))
+	out = append(out, app1...)
+	out = append(out, 0xFF, 0xFE)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(com)+2))
+	out = append(out, com...)
+	return append(out, data[2:]...)
+}
+
+// pngChunk 生成 PNG 数据块
+func pngChunk(typ string, data []byte) []byte {
+	out := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	out = append(out, typ...)
+	out = append(out, data...)
+	return binary.BigEndian.AppendUint32(out, crc32.ChecksumIEEE(append([]byte(typ), data...)))
+}
+
+// pngWithExif 生成在 IHDR 后插入 eXIf 和 tEXt 块的 PNG
+func pngWithExif(t *testing.T, img image.Image, orientation int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	ihdrEnd := 8 + 12 + 13
+	out := append([]byte{}, data[:ihdrEnd]...)
+	out = append(out, pngChunk("eXIf", exifWithGPS(orientation))...)
+	out = append(out, pngChunk("tEXt", []byte("Author\x00someone"))...)
+	return append(out, data[ihdrEnd:]...)
+}
+
+// webpChunk 生成 WebP 数据块（奇数长度时补齐填充字节）
+func webpChunk(fourCC string, data []byte) []byte {
+	out := binary.LittleEndian.AppendUint32([]byte(fourCC), uint32(len(data)))
+	out = append(out, data...)
+	if len(data)%2 == 1 {
+		out = append(out, 0)
+	}
+	return out
+}
+
+// webpFile 将数据块组装为 RIFF 容器
+func webpFile(chunks ...[]byte) []byte {
+	body := []byte("WEBP")
+	for _, chunk := range chunks {
+		body = append(body, chunk...)
+	}
+	out := binary.LittleEndian.AppendUint32([]byte("RIFF"), uint32(len(body)))
+	return append(out, body...)
+}
+
+// webpWithExif 生成带 VP8X、EXIF 和 XMP 块的 WebP（图像数据为占位内容，只用于测试块处理）
+func webpWithExif(orientation int) []byte {
+	vp8x := make([]byte, 10)
+	vp8x[0] = 0x08 | 0x04
+	return webpFile(
+		webpChunk("VP8X", vp8x),
+		webpChunk("VP8L", []byte{0x2f, 0x00, 0x00, 0x00, 0x00}),
+		webpChunk("EXIF", exifWithGPS(orientation)),
+		webpChunk("XMP ", []byte("<x:xmpmeta/>")),
+	)
+}
+
+func TestStripMetadataMalformed(t *testing.T) {
+	validJPEG := jpegWithExif(t, testImage(4, 2), 1)
+	validPNG := pngWithExif(t, testImage(4, 2), 1)
+	tests := []struct {
+		name   string
+		format string
+		data   []byte
+	}{
+		{"jpeg 空数据", "jpeg", nil},
+		{"jpeg 缺少 SOI", "jpeg", []byte("not a jpeg file")},
+		{"jpeg 只有 SOI", "jpeg", []byte{0xFF, 0xD8}},
+		{"jpeg 段长度越界", "jpeg", []byte{0xFF, 0xD8, 0xFF, 0xE1, 0xFF, 0xFF, 0x00}},
+		{"jpeg 段长度小于 2", "jpeg", []byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x01, 0x00, 0x00}},
+		{"jpeg 段标记错误", "jpeg", []byte{0xFF, 0xD8, 0x00, 0xE0, 0x00, 0x04, 0x00, 0x00}},
+		{"jpeg 截断在 SOS 之前", "jpeg", validJPEG[:30]},
+		{"png 空数据", "png", nil},
+		{"png 签名错误", "png", []byte("\x89PNG\r\n\x1b\n0000")},
+		{"png 块长度越界", "png", append([]byte("\x89PNG\r\n\x1a\n\xff\xff\xff\xffIHDR"), make([]byte, 8)...)},
+		{"png 截断在块中间", "png", validPNG[:40]},
+		{"webp 空数据", "webp", nil},
+		{"webp 签名错误", "webp", []byte("RIFF\x04\x00\x00\x00WAVE")},
+		{"webp VP8X 截断", "webp", []byte("RIFF\x0c\x00\x00\x00WEBPVP8X\x0a\x00\x00\x00")},
+		{"webp VP8X 长度不足", "webp", webpFile(webpChunk("VP8X", make([]byte, 4)))},
+		{"webp 块长度越界", "webp", []byte("RIFF\x10\x00\x00\x00WEBPVP8L\xff\xff\xff\x7f\x2f\x00\x00\x00")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := StripMetadata(tt.data, tt.format, 85)
+			if err == nil {
+				t.Fatalf("期望返回错误，实际返回 %d 字节", len(out))
+			}
+		})
+	}
+}
+
+func TestStripJPEG(t *testing.T) {
+	data := jpegWithExif(t, testImage(4, 2), 1)
+	out, err := StripMetadata(data, "jpeg", 85)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(out, []byte("Exif\x00\x00")) || bytes.Contains(out, []byte("secret comment")) {
+		t.Error("EXIF 或注释段未被清除")
+	}
+	if _, _, err = Decode(out); err != nil {
+		t.Errorf("清除后无法解码: %v", err)
+	}
+}
+
+func TestStripJPEGOrientation(t *testing.T) {
+	data := jpegWithExif(t, testImage(4, 2), 6)
+	if o := Orientation(data, "jpeg"); o != 6 {
+		t.Fatalf("Orientation = %d, 期望 6", o)
+	}
+	out, err := StripMetadata(data, "jpeg", 95)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o := Orientation(out, "jpeg"); o != 0 {
+		t.Errorf("清除后仍有方向标记 %d", o)
+	}
+	img, _, err := Decode(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 2 || b.Dy() != 4 {
+		t.Errorf("旋转后尺寸 %dx%d, 期望 2x4", b.Dx(), b.Dy())
+	}
+}
+
+func TestStripPNG(t *testing.T) {
+	data := pngWithExif(t, testImage(4, 2), 1)
+	out, err := StripMetadata(data, "png", 85)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(out, []byte("eXIf")) || bytes.Contains(out, []byte("tEXt")) {
+		t.Error("eXIf 或文本块未被清除")
+	}
+	if _, _, err = Decode(out); err != nil {
+		t.Errorf("清除后无法解码: %v", err)
+	}
+}
+
+func TestStripPNGOrientation(t *testing.T) {
+	data := pngWithExif(t, testImage(4, 2), 6)
+	if o := Orientation(data, "png"); o != 6 {
+		t.Fatalf("Orientation = %d, 期望 6", o)
+	}
+	out, err := StripMetadata(data, "png", 85)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(out, []byte("eXIf")) {
+		t.Error("eXIf 块未被清除")
+	}
+	img, _, err := Decode(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 2 || b.Dy() != 4 {
+		t.Fatalf("旋转后尺寸 %dx%d, 期望 2x4", b.Dx(), b.Dy())
+	}
+	// 顺时针旋转 90° 后原左上角像素位于右上角
+	if r, _, _, _ := img.At(1, 0).RGBA(); r>>8 != 255 {
+		t.Error("像素未按方向旋转")
+	}
+	if _, g, _, _ := img.At(1, 0).RGBA(); g>>8 != 0 {
+		t.Error("像素未按方向旋转")
+	}
+}
+
+func TestStripWebP(t *testing.T) {
+	out, err := StripMetadata(webpWithExif(1), "webp", 85)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(out, []byte("EXIF")) || bytes.Contains(out, []byte("XMP ")) {
+		t.Error("EXIF 或 XMP 块未被清除")
+	}
+	if flags := out[20]; flags&(0x08|0x04) != 0 {
+		t.Errorf("VP8X 标记位未清除: %#x", flags)
+	}
+	if size := binary.LittleEndian.Uint32(out[4:]); int(size) != len(out)-8 {
+		t.Errorf("RIFF 长度 %d, 期望 %d", size, len(out)-8)
+	}
+}
+
+func TestStripWebPOrientation(t *testing.T) {
+	out, err := StripMetadata(webpWithExif(6), "webp", 85)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(out, []byte("GPS!")) || bytes.Contains(out, []byte("XMP ")) {
+		t.Error("敏感元数据未被清除")
+	}
+	if o := Orientation(out, "webp"); o != 6 {
+		t.Errorf("保留的方向标记 = %d, 期望 6", o)
+	}
+	if flags := out[20]; flags&0x08 == 0 {
+		t.Error("VP8X 未设置 EXIF 标记位")
+	}
+}
+
+func TestStripMetadataUnknownFormat(t *testing.T) {
+	data := []byte("GIF89a")
+	out, err := StripMetadata(data, "gif", 85)
+	if err != nil || !bytes.Equal(out, data) {
+		t.Errorf("不支持的格式应原样返回")
+	}
+	if _, err = stripJPEG(data); !errors.Is(err, ErrFormat) {
+		t.Errorf("stripJPEG 错误 = %v, 期望 ErrFormat", err)
+	}
+}
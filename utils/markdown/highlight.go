@@ -0,0 +1,32 @@
+package markdown
+
+import (
+	"bytes"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/styles"
+)
+
+// chromaOptions 代码高亮输出选项：使用 class 而不是内联样式，便于切换配色
+var chromaOptions = []chromahtml.Option{
+	chromahtml.WithClasses(true),
+	chromahtml.TabWidth(4),
+}
+
+// HighlightCSS 生成指定配色主题的代码高亮样式表
+// 主题不存在时使用 fallback 主题（同样不存在时使用 chroma 的默认主题）
+func HighlightCSS(theme string, fallback string) (string, error) {
+	style, ok := styles.Registry[theme]
+	if !ok {
+		style = styles.Get(fallback)
+	}
+	var buf bytes.Buffer
+	if err := chromahtml.New(chromaOptions...).WriteCSS(&buf, style); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// HighlightThemes 可选的配色主题名称
+func HighlightThemes() []string {
+	return styles.Names()
+}
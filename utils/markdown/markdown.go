@@ -0,0 +1,66 @@
+// Package markdown 文章内容的 Markdown 渲染
+package markdown
+
+import (
+	"bytes"
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+)
+
+// renderer 全局 Markdown 渲染器（并发安全）
+// 启用 GFM（表格、任务列表、删除线、自动链接）和脚注，围栏代码块为 CommonMark 内置支持
+// 指定了语言的代码块高亮为带 class 的 HTML，配色由 HighlightCSS 提供；未知语言按普通代码块转义输出
+var renderer = goldmark.New(
+	goldmark.WithExtensions(
+		extension.GFM,
+		extension.Footnote,
+		highlighting.NewHighlighting(
+			highlighting.WithGuessLanguage(false),
+			highlighting.WithFormatOptions(chromaOptions...),
+		),
+	),
+	goldmark.WithParserOptions(
+		parser.WithAutoHeadingID(), // 为标题生成锚点，规则见 anchorIDs
+	),
+	goldmark.WithRendererOptions(
+		html.WithUnsafe(), // 保留原始 HTML，由调用方按用户角色清理
+	),
+)
+
+// Result 渲染结果
+type Result struct {
+	HTML        string    // 渲染后的 HTML
+	Toc         []TocItem // 由标题生成的目录
+	WordCount   int       // 字数（每个中日韩文字和每个西文单词各计 1）
+	ReadingTime int       // 预计阅读时间（分钟）
+}
+
+// Options 渲染参数
+type Options struct {
+	CjkPerMinute   int // 估算阅读时间时每分钟阅读的中日韩文字数
+	WordsPerMinute int // 估算阅读时间时每分钟阅读的西文单词数
+}
+
+// Render 将 Markdown 源文本渲染为 HTML，并返回目录和字数统计
+// 相同输入和参数始终得到相同输出；源文本中的原始 HTML 原样输出，保存前必须经过 sanitize 清理
+func Render(source string, opts Options) (Result, error) {
+	src := []byte(source)
+	ctx := parser.NewContext(parser.WithIDs(newAnchorIDs()))
+	doc := renderer.Parser().Parse(text.NewReader(src), parser.WithContext(ctx))
+
+	var buf bytes.Buffer
+	if err := renderer.Renderer().Render(&buf, src, doc); err != nil {
+		return Result{}, err
+	}
+	cjk, words := countWords(doc, src)
+	return Result{
+		HTML:        buf.String(),
+		Toc:         collectToc(doc, src),
+		WordCount:   cjk + words,
+		ReadingTime: readingTime(cjk, words, opts),
+	}, nil
+}
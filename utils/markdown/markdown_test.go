@@ -0,0 +1,155 @@
+package markdown
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testOptions 测试使用的阅读速度
+var testOptions = Options{CjkPerMinute: 400, WordsPerMinute: 200}
+
+func TestRenderToc(t *testing.T) {
+	source := "# 你好 World\n\n## 你好 World\n\n### Hello, *Go*!\n\n## !!!\n\n## heading\n"
+	result, err := Render(source, testOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []TocItem{
+		{Level: 1, Text: "你好 World", Anchor: "你好-world"},
+		{Level: 2, Text: "你好 World", Anchor: "你好-world-1"},
+		{Level: 3, Text: "Hello, Go!", Anchor: "hello-go"},
+		{Level: 2, Text: "!!!", Anchor: "heading"},
+		{Level: 2, Text: "heading", Anchor: "heading-1"},
+	}
+	if !reflect.DeepEqual(result.Toc, want) {
+		t.Errorf("Toc = %+v\n期望 %+v", result.Toc, want)
+	}
+	for _, item := range want {
+		if !strings.Contains(result.HTML, `id="`+item.Anchor+`"`) {
+			t.Errorf("HTML 中缺少锚点 %q", item.Anchor)
+		}
+	}
+}
+
+func TestRenderTocEmpty(t *testing.T) {
+	result, err := Render("没有标题的正文", testOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Toc == nil || len(result.Toc) != 0 {
+		t.Errorf("Toc = %#v, 期望空列表", result.Toc)
+	}
+}
+
+func TestRenderStats(t *testing.T) {
+	tests := []struct {
+		name      string
+		source    string
+		wordCount int
+	}{
+		{"空文档", "", 0},
+		{"中文", "中文测试", 4},
+		{"西文单词", "Hello, it's a well-known fact.", 5},
+		{"中英混排", "使用 Go 语言", 5},
+		{"代码块计入", "```\nfunc main\n```", 2},
+		{"原始 HTML 不计入", "<div>raw html words</div>\n\n正文", 2},
+		{"链接地址不计入", "[文档](https://example.com/a-b-c)", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Render(tt.source, testOptions)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.WordCount != tt.wordCount {
+				t.Errorf("WordCount = %d, 期望 %d", result.WordCount, tt.wordCount)
+			}
+		})
+	}
+}
+
+func TestReadingTime(t *testing.T) {
+	tests := []struct {
+		cjk, words int
+		want       int
+	}{
+		{0, 0, 0},
+		{1, 0, 1},
+		{400, 0, 1},
+		{401, 0, 2},
+		{0, 200, 1},
+		{400, 200, 2},
+		{4000, 0, 10},
+	}
+	for _, tt := range tests {
+		if got := readingTime(tt.cjk, tt.words, testOptions); got != tt.want {
+			t.Errorf("readingTime(%d, %d) = %d, 期望 %d", tt.cjk, tt.words, got, tt.want)
+		}
+	}
+	// 阅读速度为 0 时不能除零
+	if got := readingTime(10, 10, Options{}); got < 1 {
+		t.Errorf("readingTime 速度为 0 时 = %d", got)
+	}
+}
+
+func TestRenderHighlight(t *testing.T) {
+	result, err := Render("```go\nfunc main() {}\n```\n", testOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`<pre class="chroma">`, `<span class="kd">func</span>`, `<span class="nf">main</span>`} {
+		if !strings.Contains(result.HTML, want) {
+			t.Errorf("高亮结果缺少 %q:\n%s", want, result.HTML)
+		}
+	}
+	if strings.Contains(result.HTML, "style=") {
+		t.Error("高亮结果不应包含内联样式")
+	}
+}
+
+func TestRenderUnknownLanguage(t *testing.T) {
+	result, err := Render("```nosuchlang\n<b>x</b>\n```\n", testOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `<pre><code class="language-nosuchlang">&lt;b&gt;x&lt;/b&gt;` + "\n</code></pre>"
+	if !strings.Contains(result.HTML, want) {
+		t.Errorf("未知语言应按普通代码块转义输出:\n%s", result.HTML)
+	}
+}
+
+func TestRenderDeterministic(t *testing.T) {
+	source := "# 标题\n\n## 标题\n\n正文 text\n\n```go\nx := 1\n```\n\n- [x] 任务\n\n| a | b |\n|---|---|\n| 1 | 2 |\n"
+	first, err := Render(source, testOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		again, err := Render(source, testOptions)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(first, again) {
+			t.Fatalf("第 %d 次渲染结果不同", i+2)
+		}
+	}
+}
+
+func TestHighlightCSS(t *testing.T) {
+	css, err := HighlightCSS("monokai", "github")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(css, ".chroma") {
+		t.Error("样式表缺少 .chroma 选择器")
+	}
+	fallback, err := HighlightCSS("no-such-theme", "github")
+	if err != nil {
+		t.Fatal(err)
+	}
+	github, _ := HighlightCSS("github", "")
+	if fallback != github {
+		t.Error("主题不存在时应使用 fallback 主题")
+	}
+}
@@ -0,0 +1,76 @@
+package markdown
+
+import (
+	"github.com/yuin/goldmark/ast"
+	"unicode"
+)
+
+// countWords 统计文档正文的字数，返回中日韩文字数和西文单词数
+// 统计范围包括正文和代码块，不包括原始 HTML 和链接地址
+func countWords(doc ast.Node, source []byte) (cjk int, words int) {
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch t := n.(type) {
+		case *ast.Text:
+			c, w := countText(string(t.Segment.Value(source)))
+			cjk, words = cjk+c, words+w
+		case *ast.String:
+			c, w := countText(string(t.Value))
+			cjk, words = cjk+c, words+w
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			lines := n.Lines()
+			for i := 0; i < lines.Len(); i++ {
+				line := lines.At(i)
+				c, w := countText(string(line.Value(source)))
+				cjk, words = cjk+c, words+w
+			}
+		case *ast.HTMLBlock, *ast.RawHTML:
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return cjk, words
+}
+
+// countText 统计一段文本：每个中日韩文字计 1，连续的字母和数字（可含 ' 和 -）计为 1 个单词
+func countText(s string) (cjk int, words int) {
+	inWord := false
+	for _, r := range s {
+		switch {
+		case isCJK(r):
+			cjk++
+			inWord = false
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			if !inWord {
+				words++
+			}
+			inWord = true
+		case inWord && (r == '\'' || r == '’' || r == '-'):
+			// 单词内部的撇号和连字符不拆分单词
+		default:
+			inWord = false
+		}
+	}
+	return cjk, words
+}
+
+// isCJK 判断是否为中日韩文字
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// readingTime 按指定的阅读速度估算阅读时间（分钟，向上取整），有内容时至少 1 分钟
+func readingTime(cjk int, words int, opts Options) int {
+	if cjk == 0 && words == 0 {
+		return 0
+	}
+	seconds := ceilDiv(cjk*60, max(opts.CjkPerMinute, 1)) + ceilDiv(words*60, max(opts.WordsPerMinute, 1))
+	return max((seconds+59)/60, 1)
+}
+
+// ceilDiv 向上取整的整数除法
+func ceilDiv(a int, b int) int {
+	return (a + b - 1) / b
+}
@@ -0,0 +1,104 @@
+package markdown
+
+import (
+	"github.com/yuin/goldmark/ast"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// TocItem 文章目录条目
+type TocItem struct {
+	Level  int    `json:"level"`  // 标题级别（1-6）
+	Text   string `json:"text"`   // 标题文本
+	Anchor string `json:"anchor"` // 标题锚点 id
+}
+
+// anchorIDs 标题锚点生成器，每次渲染使用新的实例
+// 保留字母和数字（包括中日韩文字），空白和连字符合并为一个 "-"，其余标点移除；
+// 重复的锚点依次追加 "-1"、"-2"
+type anchorIDs struct {
+	used map[string]bool
+}
+
+func newAnchorIDs() *anchorIDs {
+	return &anchorIDs{used: make(map[string]bool)}
+}
+
+// Generate 根据标题文本生成唯一锚点
+func (s *anchorIDs) Generate(value []byte, _ ast.NodeKind) []byte {
+	base := slugify(string(value))
+	if base == "" {
+		base = "heading"
+	}
+	id := base
+	for i := 1; s.used[id]; i++ {
+		id = base + "-" + strconv.Itoa(i)
+	}
+	s.used[id] = true
+	return []byte(id)
+}
+
+// Put 记录已使用的锚点
+func (s *anchorIDs) Put(value []byte) {
+	s.used[string(value)] = true
+}
+
+// slugify 将标题文本转换为锚点
+func slugify(text string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsNumber(r) || r == '_':
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			dash = false
+			b.WriteRune(r)
+		case unicode.IsSpace(r) || r == '-':
+			dash = true
+		}
+	}
+	return b.String()
+}
+
+// collectToc 按文档顺序收集带锚点的标题
+func collectToc(doc ast.Node, source []byte) []TocItem {
+	toc := []TocItem{}
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := n.(*ast.Heading)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+		id, ok := heading.AttributeString("id")
+		if anchor, isBytes := id.([]byte); ok && isBytes {
+			toc = append(toc, TocItem{
+				Level:  heading.Level,
+				Text:   strings.TrimSpace(nodeText(heading, source)),
+				Anchor: string(anchor),
+			})
+		}
+		return ast.WalkSkipChildren, nil
+	})
+	return toc
+}
+
+// nodeText 拼接节点下的纯文本内容
+func nodeText(n ast.Node, source []byte) string {
+	var b strings.Builder
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		switch t := c.(type) {
+		case *ast.Text:
+			b.Write(t.Segment.Value(source))
+			if t.SoftLineBreak() || t.HardLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(t.Value)
+		default:
+			b.WriteString(nodeText(c, source))
+		}
+	}
+	return b.String()
+}
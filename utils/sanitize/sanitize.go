@@ -0,0 +1,94 @@
+// Package sanitize 用户提交内容的 HTML 清理
+// 文章渲染结果、摘要和评论在保存前都需要经过清理，防止存储型 XSS
+package sanitize
+
+import (
+	"github.com/microcosm-cc/bluemonday"
+	"regexp"
+	"strings"
+)
+
+// 可选的清理策略名称
+const (
+	PolicyUGC    = "ugc"    // 允许常见排版、图片、表格和链接
+	PolicyBasic  = "basic"  // 只允许文本排版、列表、代码和链接，不允许图片等外部资源
+	PolicyStrict = "strict" // 移除全部标签，只保留文本
+)
+
+// classPattern 允许的 class 取值（代码高亮、脚注等使用）
+var classPattern = regexp.MustCompile(`^[a-zA-Z0-9\-_ ]+$`)
+
+// idPattern 允许的 id 取值（标题锚点、脚注等使用）
+var idPattern = regexp.MustCompile(`^[\p{L}\p{N}:\-_.]+$`)
+
+// policies 预先构建的清理策略（构建后并发安全）
+var policies = map[string]*bluemonday.Policy{
+	PolicyUGC:    ugcPolicy(),
+	PolicyBasic:  basicPolicy(),
+	PolicyStrict: bluemonday.StrictPolicy(),
+}
+
+// Policy 按名称获取清理策略，名称无效时使用 strict 策略
+func Policy(name string) *bluemonday.Policy {
+	if p, ok := policies[name]; ok {
+		return p
+	}
+	return policies[PolicyStrict]
+}
+
+// HTML 按指定名称的策略清理 HTML
+func HTML(s string, policy string) string {
+	return Policy(policy).Sanitize(s)
+}
+
+// Text 将内容转换为纯文本：移除全部标签，<、>、& 等字符保持转义后的实体形式
+// 结果不含任何标签，可以直接作为 HTML 文本输出
+func Text(s string) string {
+	return policies[PolicyStrict].Sanitize(s)
+}
+
+// Truncate 将 Text 的结果截断到 n 个字符，不会从中间截断字符实体
+func Truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	s = string(runes[:n])
+	if i := strings.LastIndexByte(s, '&'); i >= 0 && !strings.Contains(s[i:], ";") {
+		s = s[:i]
+	}
+	return s
+}
+
+// ugcPolicy 在 bluemonday 的 UGC 策略基础上允许 Markdown 渲染结果需要的属性
+func ugcPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	allowMarkdown(p)
+	return p
+}
+
+// basicPolicy 只允许文本排版相关的标签，链接强制 nofollow 并在新窗口打开
+func basicPolicy() *bluemonday.Policy {
+	p := bluemonday.NewPolicy()
+	p.AllowElements("p", "br", "hr", "b", "strong", "i", "em", "del", "s", "sup", "sub",
+		"blockquote", "pre", "code", "span", "ul", "ol", "li",
+		"h1", "h2", "h3", "h4", "h5", "h6",
+		"table", "thead", "tbody", "tr", "th", "td")
+	p.AllowAttrs("id").Matching(idPattern).OnElements("li", "sup")
+	p.AllowAttrs("align").Matching(regexp.MustCompile(`^(left|center|right)$`)).OnElements("th", "td")
+	p.AllowStandardURLs()
+	p.AllowAttrs("href").OnElements("a")
+	p.RequireNoFollowOnLinks(true)
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+	allowMarkdown(p)
+	return p
+}
+
+// allowMarkdown 允许 Markdown 渲染结果中的标题锚点、任务列表复选框、代码高亮和脚注属性
+func allowMarkdown(p *bluemonday.Policy) {
+	p.AllowAttrs("id").Matching(idPattern).OnElements("h1", "h2", "h3", "h4", "h5", "h6")
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").OnElements("input")
+	p.AllowAttrs("class").Matching(classPattern).OnElements("pre", "code", "span", "div", "a", "sup", "li", "input")
+	p.AllowAttrs("role").Matching(regexp.MustCompile(`^doc-[a-z]+$`)).OnElements("a", "div", "section")
+}
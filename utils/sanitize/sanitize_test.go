@@ -0,0 +1,171 @@
+package sanitize
+
+import (
+	"golang.org/x/net/html"
+	"strings"
+	"testing"
+)
+
+// xssCorpus 常见 XSS 载荷，清理结果由 checkSafe 检查
+var xssCorpus = []struct {
+	name    string
+	payload string
+}{
+	{"script 标签", `<script>alert(1)</script>`},
+	{"大小写混合 script", `<ScRiPt>alert(1)</sCrIpT>`},
+	{"img onerror", `<img src=x onerror=alert(1)>`},
+	{"svg onload", `<svg onload=alert(1)><circle r="1"/></svg>`},
+	{"body onload", `<body onload=alert(1)>`},
+	{"javascript 链接", `<a href="javascript:alert(1)">x</a>`},
+	{"编码的 javascript 链接", `<a href="&#106;avascript:alert(1)">x</a>`},
+	{"空白混淆的 javascript 链接", `<a href="java	script:alert(1)">x</a>`},
+	{"vbscript 链接", `<a href="vbscript:msgbox(1)">x</a>`},
+	{"data 链接", `<a href="data:text/html;base64,PHNjcmlwdD5hbGVydCgxKTwvc2NyaXB0Pg==">x</a>`},
+	{"iframe", `<iframe src="https://evil.example/"></iframe>`},
+	{"iframe srcdoc", `<iframe srcdoc="<script>alert(1)</script>"></iframe>`},
+	{"object", `<object data="evil.swf"></object>`},
+	{"embed", `<embed src="evil.swf">`},
+	{"form action", `<form action="javascript:alert(1)"><button>x</button></form>`},
+	{"style 标签", `<style>body{background:url(javascript:alert(1))}</style>`},
+	{"style 属性", `<p style="background:url(javascript:alert(1))">x</p>`},
+	{"meta refresh", `<meta http-equiv="refresh" content="0;url=javascript:alert(1)">`},
+	{"base href", `<base href="javascript:alert(1)//">`},
+	{"link import", `<link rel="import" href="https://evil.example/x.html">`},
+	{"事件属性在允许的标签上", `<p onclick="alert(1)" onmouseover="alert(1)">x</p>`},
+	{"代码块 class 中的事件", `<code class="x" onfocus="alert(1)" autofocus>x</code>`},
+	{"复选框事件", `<input type="checkbox" onchange="alert(1)">`},
+	{"伪装的复选框", `<input type="text" value="x" onfocus="alert(1)">`},
+	{"未闭合标签", `<img src=x onerror=alert(1)//`},
+	{"注释中的载荷", `<!--<img src=x onerror=alert(1)>-->`},
+	{"math 混淆", `<math><mtext><table><mglyph><style><img src=x onerror=alert(1)>`},
+	{"noscript 混淆", `<noscript><p title="</noscript><img src=x onerror=alert(1)>">`},
+	{"标题 id 注入", `<h2 id="x&quot; onmouseover=&quot;alert(1)">x</h2>`},
+	{"role 注入", `<a role="doc-noteref&quot; onclick=&quot;alert(1)" href="#x">x</a>`},
+}
+
+// forbiddenTags 清理结果中不允许出现的标签
+var forbiddenTags = map[string]bool{
+	"script": true, "iframe": true, "object": true, "embed": true, "form": true, "style": true,
+	"meta": true, "base": true, "link": true, "svg": true, "math": true, "noscript": true, "body": true,
+}
+
+// checkSafe 解析清理结果，检查是否存在危险标签、事件属性、内联样式或脚本链接
+func checkSafe(t *testing.T, out string) {
+	t.Helper()
+	z := html.NewTokenizer(strings.NewReader(out))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := z.Token()
+			if forbiddenTags[token.Data] {
+				t.Errorf("清理结果包含 <%s>: %s", token.Data, out)
+			}
+			for _, attr := range token.Attr {
+				if strings.HasPrefix(attr.Key, "on") || attr.Key == "style" || attr.Key == "srcdoc" || attr.Key == "formaction" {
+					t.Errorf("清理结果包含属性 %s: %s", attr.Key, out)
+				}
+				value := strings.ToLower(strings.Join(strings.Fields(attr.Val), ""))
+				for _, scheme := range []string{"javascript:", "vbscript:", "data:"} {
+					if strings.HasPrefix(value, scheme) {
+						t.Errorf("清理结果包含 %s 链接: %s", scheme, out)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestXSSCorpus(t *testing.T) {
+	for _, policy := range []string{PolicyUGC, PolicyBasic, PolicyStrict} {
+		for _, tt := range xssCorpus {
+			t.Run(policy+"/"+tt.name, func(t *testing.T) {
+				checkSafe(t, HTML(tt.payload, policy))
+			})
+		}
+	}
+}
+
+func TestPolicyAllowsMarkdown(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		input  string
+		want   string
+	}{
+		{"中文标题锚点", PolicyUGC, `<h2 id="你好-world">你好</h2>`, `<h2 id="你好-world">你好</h2>`},
+		{"中文标题锚点 basic", PolicyBasic, `<h2 id="你好-world">你好</h2>`, `<h2 id="你好-world">你好</h2>`},
+		{"代码高亮 class", PolicyBasic, `<pre class="chroma"><code><span class="kd">func</span></code></pre>`,
+			`<pre class="chroma"><code><span class="kd">func</span></code></pre>`},
+		{"任务列表复选框", PolicyBasic, `<input checked="" disabled="" type="checkbox">`, `<input checked="" disabled="" type="checkbox">`},
+		{"脚注引用", PolicyUGC, `<sup id="fnref:1"><a href="#fn:1" class="footnote-ref" role="doc-noteref">1</a></sup>`,
+			`<sup id="fnref:1"><a href="#fn:1" class="footnote-ref" role="doc-noteref" rel="nofollow">1</a></sup>`},
+		{"ugc 允许图片", PolicyUGC, `<img src="https://example.com/a.png" alt="a">`, `<img src="https://example.com/a.png" alt="a">`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if out := HTML(tt.input, tt.policy); out != tt.want {
+				t.Errorf("HTML() = %s\n期望 %s", out, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyRestrictions(t *testing.T) {
+	img := `<p>x<img src="https://example.com/a.png"></p>`
+	if out := HTML(img, PolicyBasic); strings.Contains(out, "<img") {
+		t.Errorf("basic 策略不应允许图片: %s", out)
+	}
+	if out := HTML(`<a href="https://example.com/">x</a>`, PolicyBasic); !strings.Contains(out, `rel="nofollow noopener"`) || !strings.Contains(out, `target="_blank"`) {
+		t.Errorf("basic 策略的外部链接应强制 nofollow 并在新窗口打开: %s", out)
+	}
+	if out := HTML(`<p><b>粗体</b> &amp; 文本</p>`, PolicyStrict); out != `粗体 &amp; 文本` {
+		t.Errorf("strict 策略应移除全部标签: %s", out)
+	}
+	if out := HTML(img, "no-such-policy"); out != "x" {
+		t.Errorf("无效策略名应使用 strict 策略: %s", out)
+	}
+}
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"纯文本", "纯文本"},
+		{"A & B < C", "A &amp; B &lt; C"},
+		{"<b>粗体</b>和<script>alert(1)</script>文本", "粗体和文本"},
+		{"&lt;b&gt; 实体", "&lt;b&gt; 实体"},
+		{"&lt;img src=x onerror=alert(1)&gt;", "&lt;img src=x onerror=alert(1)&gt;"},
+		{"&amp;lt;script&amp;gt;", "&amp;lt;script&amp;gt;"},
+	}
+	for _, tt := range tests {
+		out := Text(tt.input)
+		if out != tt.want {
+			t.Errorf("Text(%q) = %q, 期望 %q", tt.input, out, tt.want)
+		}
+		if strings.ContainsAny(out, "<>") {
+			t.Errorf("Text(%q) = %q, 输出中不应包含 < 或 >", tt.input, out)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		input string
+		n     int
+		want  string
+	}{
+		{"短文本", 10, "短文本"},
+		{"中文摘要截断", 4, "中文摘要"},
+		{"A &amp; B", 4, "A "},
+		{"A &amp; B", 7, "A &amp;"},
+		{"&lt;b&gt;", 6, "&lt;b"},
+	}
+	for _, tt := range tests {
+		if out := Truncate(tt.input, tt.n); out != tt.want {
+			t.Errorf("Truncate(%q, %d) = %q, 期望 %q", tt.input, tt.n, out, tt.want)
+		}
+	}
+}
@@ -3,6 +3,9 @@ package utils
 import (
 	"fmt"
 	"gopkg.in/ini.v1" // 用于读取INI格式的配置文件
+	"os"
+	"testing"
+	"time"
 )
 
 // 全局配置变量（包级作用域）
@@ -25,6 +28,53 @@ var (
 	SecretKey  string // 七牛云SecretKey
 	Bucket     string // 存储空间名称
 	QiniuSever string // 七牛云服务地址
+
+	// StorageType 文件存储配置
+	StorageType string // 存储后端类型（local/s3/qiniu）
+	LocalPath   string // 本地存储目录
+	LocalRoute  string // 本地存储静态文件路由
+	LocalUrl    string // 本地存储文件访问URL前缀
+
+	// S3Endpoint S3 兼容存储配置
+	S3Endpoint  string // 服务地址（host:port，不含协议）
+	S3AccessKey string // AccessKey
+	S3SecretKey string // SecretKey
+	S3Bucket    string // 存储桶名称
+	S3Region    string // 区域
+	S3UseSSL    bool   // 是否使用HTTPS
+	S3Url       string // 文件访问URL前缀（为空时使用 endpoint/bucket）
+
+	// UploadMaxSize 上传校验配置
+	UploadMaxSize    int64         // 单个文件大小上限（字节）
+	UploadAllowTypes []string      // 允许上传的文件类型（按内容识别的 MIME 类型）
+	MediaGCInterval  time.Duration // 未引用媒体清理任务的执行间隔（0 表示不清理）
+	MediaGracePeriod time.Duration // 媒体上传后的宽限期，宽限期内即使未被引用也不清理
+	ImageWidths      []int         // 上传图片时生成的缩略图宽度列表
+	ImageQuality     int           // 重新编码图片时的 JPEG 质量（1-100）
+	ImageMaxSize     int64         // 需要在内存中处理（清除元数据、生成缩略图）的图片大小上限（字节）
+	StripMeta        bool          // 是否清除上传图片中的 EXIF 等元数据
+	KeepMetaRoles    []int         // 上传图片时保留元数据的用户角色
+
+	// ChunkDir 分片上传配置
+	ChunkDir     string        // 分片暂存目录
+	ChunkSize    int64         // 分片大小上限（字节）
+	ChunkMinSize int64         // 分片大小下限（字节，最后一个分片除外）
+	ChunkMaxSize int64         // 分片上传的文件大小上限（字节）
+	ChunkExpire  time.Duration // 上传会话无活动后的过期时间
+
+	PresignExpire time.Duration // 浏览器直传凭证有效期
+
+	// SanitizeAdminPolicy HTML 清理配置（可选 ugc/basic/strict）
+	SanitizeAdminPolicy string // 管理员提交内容使用的清理策略
+	SanitizeUserPolicy  string // 其他角色提交内容使用的清理策略
+
+	HighlightTheme string // 代码高亮默认配色主题
+	CjkPerMinute   int    // 估算阅读时间时每分钟阅读的中日韩文字数
+	WordsPerMinute int    // 估算阅读时间时每分钟阅读的西文单词数
+
+	ScheduleInterval time.Duration // 定时发布任务的检查间隔（0 表示不启用）
+	AutosaveMaxAge   time.Duration // 自动保存内容的保留时间，超过后清理（0 表示不清理）
+	LockTTL          time.Duration // 文章编辑锁的有效期，超过后未续期自动失效
 )
 
 // 包初始化函数（自动执行）
@@ -32,12 +82,22 @@ func init() {
 	// 加载配置文件（路径：config/config.ini）
 	file, err := ini.Load("config/config.ini")
 	if err != nil {
-		fmt.Println("配置文件读取错误，请检查文件路径:", err)
+		// 缺少配置文件时不能以默认密钥和数据库配置启动，只有单元测试使用默认配置
+		if !testing.Testing() {
+			fmt.Println("配置文件读取错误，请检查文件路径:", err)
+			os.Exit(1)
+		}
+		file = ini.Empty()
 	}
 	// 分别加载不同配置模块
-	LoadServer(file) // 加载服务器配置
-	LoadData(file)   // 加载数据库配置
-	LoadQiniu(file)  // 加载七牛云配置
+	LoadServer(file)   // 加载服务器配置
+	LoadData(file)     // 加载数据库配置
+	LoadQiniu(file)    // 加载七牛云配置
+	LoadStorage(file)  // 加载文件存储配置
+	LoadUpload(file)   // 加载上传校验配置
+	LoadSanitize(file) // 加载 HTML 清理配置
+	LoadMarkdown(file) // 加载文章渲染配置
+	LoadArticle(file)  // 加载文章管理配置
 }
 
 // LoadServer 加载服务器配置模块
@@ -68,3 +128,72 @@ func LoadQiniu(file *ini.File) {
 	Bucket = section.Key("Bucket").String()         // 存储桶名称（必须配置）
 	QiniuSever = section.Key("QiniuSever").String() // 服务地址（必须配置）
 }
+
+// LoadStorage 加载文件存储配置模块
+func LoadStorage(file *ini.File) {
+	section := file.Section("storage")
+	StorageType = section.Key("Type").MustString("qiniu")                                     // 默认七牛云（兼容原有配置）
+	LocalPath = section.Key("LocalPath").MustString("upload")                                 // 默认保存到 upload 目录
+	LocalRoute = section.Key("LocalRoute").MustString("/upload")                              // 默认静态路由 /upload
+	LocalUrl = section.Key("LocalUrl").MustString("http://localhost" + HttpPort + LocalRoute) // 默认本机访问地址
+
+	section = file.Section("s3")
+	S3Endpoint = section.Key("Endpoint").String()   // 服务地址（使用 s3 时必须配置）
+	S3AccessKey = section.Key("AccessKey").String() // AccessKey（使用 s3 时必须配置）
+	S3SecretKey = section.Key("SecretKey").String() // SecretKey（使用 s3 时必须配置）
+	S3Bucket = section.Key("Bucket").String()       // 存储桶名称（使用 s3 时必须配置）
+	S3Region = section.Key("Region").String()       // 区域（可选）
+	S3UseSSL = section.Key("UseSSL").MustBool(true) // 默认使用HTTPS
+	S3Url = section.Key("Url").String()             // 访问URL前缀（可选）
+}
+
+// LoadUpload 加载上传校验配置模块
+func LoadUpload(file *ini.File) {
+	section := file.Section("upload")
+	UploadMaxSize = section.Key("MaxSize").MustInt64(10) << 20 // 默认 10MB（配置单位为MB）
+	UploadAllowTypes = section.Key("AllowTypes").Strings(",")  // 允许的 MIME 类型，逗号分隔
+	if len(UploadAllowTypes) == 0 {
+		UploadAllowTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp", "application/pdf"}
+	}
+	MediaGCInterval = time.Duration(section.Key("MediaGCInterval").MustInt(24)) * time.Hour   // 默认每 24 小时清理一次
+	MediaGracePeriod = time.Duration(section.Key("MediaGracePeriod").MustInt(72)) * time.Hour // 默认宽限期 72 小时
+	ImageWidths = section.Key("ImageWidths").Ints(",")                                        // 缩略图宽度，逗号分隔
+	if len(ImageWidths) == 0 {
+		ImageWidths = []int{320, 768, 1280}
+	}
+	ImageQuality = section.Key("ImageQuality").MustInt(85)         // 默认质量 85
+	ImageMaxSize = section.Key("ImageMaxSize").MustInt64(20) << 20 // 默认 20MB（配置单位为MB）
+	StripMeta = section.Key("StripMeta").MustBool(true)            // 默认清除元数据
+	KeepMetaRoles = section.Key("KeepMetaRoles").Ints(",")         // 默认所有角色都清除，逗号分隔
+
+	ChunkDir = section.Key("ChunkDir").MustString("tmp/chunks")                     // 默认暂存到 tmp/chunks
+	ChunkSize = section.Key("ChunkSize").MustInt64(5) << 20                         // 默认 5MB（配置单位为MB）
+	ChunkMinSize = min(section.Key("ChunkMinSize").MustInt64(256)<<10, ChunkSize)   // 默认 256KB（配置单位为KB），不超过分片大小上限
+	ChunkMaxSize = section.Key("ChunkMaxSize").MustInt64(1024) << 20                // 默认 1GB（配置单位为MB）
+	ChunkExpire = time.Duration(section.Key("ChunkExpire").MustInt(24)) * time.Hour // 默认 24 小时
+
+	PresignExpire = time.Duration(section.Key("PresignExpire").MustInt(15)) * time.Minute // 默认 15 分钟
+}
+
+// LoadSanitize 加载 HTML 清理配置模块
+func LoadSanitize(file *ini.File) {
+	section := file.Section("sanitize")
+	SanitizeAdminPolicy = section.Key("AdminPolicy").In("ugc", []string{"ugc", "basic", "strict"}) // 默认允许图片和表格
+	SanitizeUserPolicy = section.Key("UserPolicy").In("basic", []string{"ugc", "basic", "strict"}) // 默认不允许图片等外部资源
+}
+
+// LoadMarkdown 加载文章渲染配置模块
+func LoadMarkdown(file *ini.File) {
+	section := file.Section("markdown")
+	HighlightTheme = section.Key("HighlightTheme").MustString("github") // 默认 github 配色
+	CjkPerMinute = section.Key("CjkPerMinute").MustInt(400)             // 默认每分钟 400 字
+	WordsPerMinute = section.Key("WordsPerMinute").MustInt(200)         // 默认每分钟 200 词
+}
+
+// LoadArticle 加载文章管理配置模块
+func LoadArticle(file *ini.File) {
+	section := file.Section("article")
+	ScheduleInterval = time.Duration(section.Key("ScheduleInterval").MustInt(60)) * time.Second // 默认每 60 秒检查一次
+	AutosaveMaxAge = time.Duration(section.Key("AutosaveMaxAge").MustInt(7)) * 24 * time.Hour   // 默认保留 7 天（配置单位为天）
+	LockTTL = time.Duration(section.Key("LockTTL").MustInt(120)) * time.Second                  // 默认 120 秒未续期自动失效
+}
@@ -0,0 +1,64 @@
+// Package slug 文章别名（URL 中的固定链接）生成与校验
+package slug
+
+import (
+	"github.com/mozillazg/go-pinyin"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// MaxLen 别名最大长度
+const MaxLen = 100
+
+// pattern 合法别名：小写字母、数字，以单个 "-" 分隔
+var pattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// pinyinArgs 汉字转拼音参数（不带声调，多音字取常用读音）
+var pinyinArgs = pinyin.NewArgs()
+
+// Make 根据标题生成别名
+// 汉字转写为拼音（每个字一个音节），保留 ASCII 字母和数字，其余字符作为分隔；结果为空时返回空字符串
+func Make(title string) string {
+	var words []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			words = append(words, word.String())
+			word.Reset()
+		}
+	}
+	for _, r := range strings.ToLower(title) {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			word.WriteRune(r)
+		case unicode.Is(unicode.Han, r):
+			flush()
+			if py := pinyin.SinglePinyin(r, pinyinArgs); len(py) > 0 {
+				words = append(words, py[0])
+			}
+		default:
+			flush()
+		}
+	}
+	flush()
+	return Truncate(strings.Join(words, "-"))
+}
+
+// Normalize 规范化手动设置的别名：转为小写，空白替换为 "-"
+func Normalize(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(strings.TrimSpace(s))), "-")
+}
+
+// Valid 校验别名格式
+func Valid(s string) bool {
+	return len(s) <= MaxLen && pattern.MatchString(s)
+}
+
+// Truncate 将别名截断到最大长度，并去掉末尾的 "-"
+func Truncate(s string) string {
+	if len(s) > MaxLen {
+		s = s[:MaxLen]
+	}
+	return strings.TrimRight(s, "-")
+}
@@ -0,0 +1,80 @@
+package slug
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Hello World", "hello-world"},
+		{"  Go 1.24 发布  ", "go-1-24-fa-bu"},
+		{"你好，世界！", "ni-hao-shi-jie"},
+		{"Gin+Gorm 实战", "gin-gorm-shi-zhan"},
+		{"C++ & Rust", "c-rust"},
+		{"Ünïcödé title", "n-c-d-title"},
+		{"!!!", ""},
+		{"", ""},
+		{"こんにちは", ""},
+	}
+	for _, tt := range tests {
+		if got := Make(tt.title); got != tt.want {
+			t.Errorf("Make(%q) = %q, 期望 %q", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestMakeTruncate(t *testing.T) {
+	got := Make(strings.Repeat("word ", 40))
+	if len(got) > MaxLen {
+		t.Errorf("长度 %d 超过上限 %d", len(got), MaxLen)
+	}
+	if strings.HasSuffix(got, "-") {
+		t.Errorf("截断后不应以 - 结尾: %q", got)
+	}
+	if !Valid(got) {
+		t.Errorf("生成的别名不合法: %q", got)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"My Post", "my-post"},
+		{"  spaced   out  ", "spaced-out"},
+		{"already-ok", "already-ok"},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.input); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, 期望 %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		slug string
+		want bool
+	}{
+		{"hello-world", true},
+		{"a1-b2", true},
+		{"Hello", false},
+		{"-leading", false},
+		{"trailing-", false},
+		{"double--dash", false},
+		{"中文", false},
+		{"", false},
+		{strings.Repeat("a", MaxLen), true},
+		{strings.Repeat("a", MaxLen+1), false},
+	}
+	for _, tt := range tests {
+		if got := Valid(tt.slug); got != tt.want {
+			t.Errorf("Valid(%q) = %v, 期望 %v", tt.slug, got, tt.want)
+		}
+	}
+}
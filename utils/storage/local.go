@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Local 本地文件系统存储，文件由 gin 静态路由对外提供访问
+type Local struct {
+	root    string // 文件保存目录
+	baseUrl string // 访问URL前缀
+}
+
+// NewLocal 创建本地存储，目录不存在时自动创建
+func NewLocal(root string, baseUrl string) (*Local, error) {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, err
+	}
+	return &Local{
+		root:    root,
+		baseUrl: strings.TrimRight(baseUrl, "/"),
+	}, nil
+}
+
+// Put 保存文件（先写临时文件再重命名，避免读到不完整的文件）
+func (l *Local) Put(_ context.Context, key string, r io.Reader, _ int64, _ string) (string, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return "", err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err = tmp.Close(); err != nil {
+		return "", err
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+	return l.URL(key), nil
+}
+
+// Delete 删除文件
+func (l *Local) Delete(_ context.Context, key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// URL 返回文件访问URL
+func (l *Local) URL(key string) string {
+	return l.baseUrl + "/" + key
+}
+
+// path 将 key 转换为保存路径，拒绝跳出存储目录的 key
+func (l *Local) path(key string) (string, error) {
+	if key == "" || !filepath.IsLocal(filepath.FromSlash(key)) {
+		return "", fmt.Errorf("非法的文件key: %q", key)
+	}
+	return filepath.Join(l.root, filepath.FromSlash(key)), nil
+}
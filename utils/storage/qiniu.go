@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/qiniu/go-sdk/v7/auth/qbox"
+	qiniu "github.com/qiniu/go-sdk/v7/storage"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Qiniu 七牛云存储
+type Qiniu struct {
+	zone      int    // 存储区域编号（1-华东，2-华北，3-华南）
+	accessKey string // 七牛云AccessKey
+	secretKey string // 七牛云SecretKey
+	bucket    string // 存储空间名称
+	baseUrl   string // 七牛云图片访问域名
+}
+
+// NewQiniu 创建七牛云存储
+func NewQiniu(zone int, accessKey string, secretKey string, bucket string, baseUrl string) *Qiniu {
+	return &Qiniu{
+		zone:      zone,
+		accessKey: accessKey,
+		secretKey: secretKey,
+		bucket:    bucket,
+		baseUrl:   baseUrl,
+	}
+}
+
+// Put 上传文件到七牛云存储
+func (q *Qiniu) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (string, error) {
+	// 创建上传策略（指定 key 时允许覆盖同名文件）
+	putPolicy := qiniu.PutPolicy{
+		Scope: q.bucket + ":" + key,
+	}
+	// 生成上传凭证
+	upToken := putPolicy.UploadToken(q.mac())
+
+	// 获取存储配置
+	cfg := q.config()
+
+	// 创建表单上传对象
+	formUploader := qiniu.NewFormUploader(&cfg)
+	// 初始化返回结构
+	ret := qiniu.PutRet{}
+	putExtra := qiniu.PutExtra{
+		MimeType: contentType,
+	}
+
+	err := formUploader.Put(ctx, &ret, upToken, key, r, size, &putExtra)
+	if err != nil {
+		return "", err
+	}
+	return q.URL(ret.Key), nil
+}
+
+// PresignPut 生成表单直传凭证（上传策略限制 key、大小和 MIME 类型）
+func (q *Qiniu) PresignPut(_ context.Context, key string, size int64, contentType string, expires time.Duration) (PresignedUpload, error) {
+	expiresAt := time.Now().Add(expires)
+	putPolicy := qiniu.PutPolicy{
+		Scope:      q.bucket + ":" + key,
+		Expires:    uint64(expiresAt.Unix()),
+		FsizeLimit: size,
+		MimeLimit:  contentType,
+	}
+	zone := selectZone(q.zone)
+	return PresignedUpload{
+		Method:  "POST",
+		Url:     "https://" + zone.SrcUpHosts[0],
+		Headers: map[string]string{},
+		Fields: map[string]string{
+			"token": putPolicy.UploadToken(q.mac()),
+			"key":   key,
+		},
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// Stat 查询七牛云中的文件信息
+func (q *Qiniu) Stat(_ context.Context, key string) (ObjectInfo, error) {
+	cfg := q.config()
+	bucketManager := qiniu.NewBucketManager(q.mac(), &cfg)
+	info, err := bucketManager.Stat(q.bucket, key)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	return ObjectInfo{Size: info.Fsize, ContentType: info.MimeType}, nil
+}
+
+// Open 通过访问域名读取七牛云中的文件
+func (q *Qiniu) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, q.URL(key), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("读取文件失败: %s", resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// Delete 删除七牛云中的文件
+func (q *Qiniu) Delete(_ context.Context, key string) error {
+	cfg := q.config()
+	bucketManager := qiniu.NewBucketManager(q.mac(), &cfg)
+	err := bucketManager.Delete(q.bucket, key)
+	// 612：文件不存在
+	var qErr *qiniu.ErrorInfo
+	if errors.As(err, &qErr) && qErr.Code == 612 {
+		return nil
+	}
+	return err
+}
+
+// URL 拼接完整访问URL
+func (q *Qiniu) URL(key string) string {
+	return q.baseUrl + key
+}
+
+// mac 创建Mac对象用于签名
+func (q *Qiniu) mac() *qbox.Mac {
+	return qbox.NewMac(q.accessKey, q.secretKey)
+}
+
+// config 配置七牛云存储区域
+// 返回值：storage.Config - 存储配置对象
+func (q *Qiniu) config() qiniu.Config {
+	return qiniu.Config{
+		Zone:          selectZone(q.zone), // 根据配置选择存储区域
+		UseCdnDomains: false,              // 不使用CDN域名
+		UseHTTPS:      false,              // 不使用HTTPS
+	}
+}
+
+// selectZone 根据区域编号选择七牛云存储区域
+// 参数：id - 区域编号（1-华东，2-华北，3-华南）
+// 返回值：*storage.Zone - 对应的存储区域指针
+func selectZone(id int) *qiniu.Zone {
+	switch id {
+	case 1:
+		return &qiniu.ZoneHuadong // 华东区域
+	case 2:
+		return &qiniu.ZoneHuabei // 华北区域
+	case 3:
+		return &qiniu.ZoneHuanan // 华南区域
+	default:
+		return &qiniu.ZoneHuadong // 默认华东区域
+	}
+}
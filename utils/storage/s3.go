@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// S3 S3 兼容对象存储（AWS S3、MinIO、阿里云OSS等）
+type S3 struct {
+	client  *minio.Client
+	bucket  string // 存储桶名称
+	baseUrl string // 访问URL前缀
+}
+
+// NewS3 创建 S3 兼容存储
+// baseUrl 为空时使用 endpoint/bucket 路径形式的访问地址
+func NewS3(endpoint string, accessKey string, secretKey string, bucket string, region string, useSSL bool, baseUrl string) (*S3, error) {
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
+		Secure: useSSL,
+		Region: region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if baseUrl == "" {
+		baseUrl = client.EndpointURL().String() + "/" + bucket
+	}
+	return &S3{
+		client:  client,
+		bucket:  bucket,
+		baseUrl: strings.TrimRight(baseUrl, "/"),
+	}, nil
+}
+
+// Put 上传文件到存储桶
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (string, error) {
+	_, err := s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	if err != nil {
+		return "", err
+	}
+	return s.URL(key), nil
+}
+
+// PresignPut 生成预签名 PUT 地址，Content-Type 参与签名，浏览器必须以给定的 Content-Type 上传
+// 预签名 PUT 无法限制文件大小，由回调时校验
+func (s *S3) PresignPut(ctx context.Context, key string, _ int64, contentType string, expires time.Duration) (PresignedUpload, error) {
+	u, err := s.client.PresignHeader(ctx, http.MethodPut, s.bucket, key, expires, nil, http.Header{
+		"Content-Type": []string{contentType},
+	})
+	if err != nil {
+		return PresignedUpload{}, err
+	}
+	return PresignedUpload{
+		Method:    "PUT",
+		Url:       u.String(),
+		Headers:   map[string]string{"Content-Type": contentType},
+		ExpiresAt: time.Now().Add(expires),
+	}, nil
+}
+
+// Stat 查询存储桶中的文件信息
+func (s *S3) Stat(ctx context.Context, key string) (ObjectInfo, error) {
+	info, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	return ObjectInfo{Size: info.Size, ContentType: info.ContentType}, nil
+}
+
+// Open 读取存储桶中的文件
+func (s *S3) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	return s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+}
+
+// Delete 删除存储桶中的文件（S3 删除不存在的对象不会报错）
+func (s *S3) Delete(ctx context.Context, key string) error {
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
+
+// URL 返回文件访问URL
+func (s *S3) URL(key string) string {
+	return s.baseUrl + "/" + key
+}
@@ -0,0 +1,67 @@
+// Package storage 文件存储后端（本地文件系统、S3 兼容存储、七牛云）
+package storage
+
+import (
+	"context"
+	"fmt"
+	"ginblog/utils"
+	"io"
+	"time"
+)
+
+// 存储后端类型（对应配置 [storage] Type）
+const (
+	TypeLocal = "local" // 本地文件系统
+	TypeS3    = "s3"    // S3 兼容对象存储
+	TypeQiniu = "qiniu" // 七牛云
+)
+
+// Storage 文件存储后端接口
+type Storage interface {
+	// Put 以指定 key 保存文件，返回文件访问URL
+	// contentType 为空时由存储后端自行判断
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (string, error)
+	// Delete 删除指定 key 的文件，文件不存在时不报错
+	Delete(ctx context.Context, key string) error
+	// URL 返回指定 key 的访问URL
+	URL(key string) string
+}
+
+// Presigner 支持浏览器直传的存储后端（本地存储不支持）
+type Presigner interface {
+	// PresignPut 生成指定 key 的短期直传凭证，限制文件大小和类型
+	PresignPut(ctx context.Context, key string, size int64, contentType string, expires time.Duration) (PresignedUpload, error)
+	// Stat 查询已上传文件的信息
+	Stat(ctx context.Context, key string) (ObjectInfo, error)
+	// Open 读取已上传文件的内容（回调时由服务端校验文件内容）
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+// PresignedUpload 直传凭证，浏览器按此直接向存储后端上传文件
+type PresignedUpload struct {
+	Method    string            `json:"method"`           // 请求方法（PUT 直接发送文件内容；POST 使用表单上传，文件字段名为 file）
+	Url       string            `json:"url"`              // 上传地址
+	Headers   map[string]string `json:"headers"`          // 需要附带的请求头
+	Fields    map[string]string `json:"fields,omitempty"` // 表单上传时需要附带的字段
+	ExpiresAt time.Time         `json:"expires_at"`       // 凭证过期时间
+}
+
+// ObjectInfo 存储后端中的文件信息
+type ObjectInfo struct {
+	Size        int64  // 文件大小（字节）
+	ContentType string // 文件 MIME 类型
+}
+
+// New 根据配置创建存储后端
+func New() (Storage, error) {
+	switch utils.StorageType {
+	case TypeLocal:
+		return NewLocal(utils.LocalPath, utils.LocalUrl)
+	case TypeS3:
+		return NewS3(utils.S3Endpoint, utils.S3AccessKey, utils.S3SecretKey, utils.S3Bucket, utils.S3Region, utils.S3UseSSL, utils.S3Url)
+	case TypeQiniu:
+		return NewQiniu(utils.Zone, utils.AccessKey, utils.SecretKey, utils.Bucket, utils.QiniuSever), nil
+	default:
+		return nil, fmt.Errorf("未知的存储类型: %s", utils.StorageType)
+	}
+}